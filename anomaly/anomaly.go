@@ -0,0 +1,180 @@
+package anomaly
+
+import (
+	"context"
+	"math"
+	"rating-service/config"
+	"rating-service/db"
+	"rating-service/events"
+	"rating-service/metrics"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Type of event published when a new anomaly is detected.
+const EventDetected = "anomaly.detected"
+
+type Options struct {
+	// How often stations are checked.
+	Interval time.Duration
+	// Length of the window that is compared with the baseline before it.
+	Window   time.Duration
+	Baseline time.Duration
+	// Windows with fewer ratings are never anomalous.
+	MinRatings int
+	// How many times more ratings than expected a window must have.
+	VelocityFactor float64
+	// Minimal total variation distance between distributions of stars in the
+	// window and in the baseline, from 0 for equal to 1 for disjoint distributions.
+	MinDistance float64
+	// Whether public summary of an anomalous station is frozen at the window start.
+	Freeze bool
+}
+
+// Result of comparing a window of a station with its baseline.
+type Evaluation struct {
+	Anomalous       bool
+	RatingCount     int64
+	ExpectedCount   float64
+	AverageRating   float64
+	BaselineAverage float64
+	Distance        float64
+}
+
+// Watches rating velocity and distribution of stars of every station.
+type Detector struct {
+	store     *db.Store
+	publisher events.Publisher
+	options   Options
+}
+
+func NewDetector(store *db.Store, publisher events.Publisher, options Options) *Detector {
+	return &Detector{store: store, publisher: publisher, options: options}
+}
+
+// Returns detector options from configuration.
+func OptionsFrom(config config.Config) Options {
+	return Options{
+		Interval:       config.AnomalyInterval,
+		Window:         config.AnomalyWindow,
+		Baseline:       config.AnomalyBaseline,
+		MinRatings:     config.AnomalyMinRatings,
+		VelocityFactor: config.AnomalyVelocityFactor,
+		MinDistance:    config.AnomalyMinDistance,
+		Freeze:         config.AnomalyFreeze,
+	}
+}
+
+// Checks stations every interval until context is done.
+func (d *Detector) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.Detect(ctx); err != nil {
+				zap.L().Error("Failed to detect anomalies", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Checks window that ends now for every station with enough ratings in it. Flags
+// anomalous windows and publishes an event for each new anomaly. Bounds of the
+// window come from the database clock, so they compare with creation times of
+// ratings in any time zone of the database session.
+func (d *Detector) Detect(ctx context.Context) ([]db.Anomaly, error) {
+	stats, err := d.store.GetWindowStats(ctx, db.WindowStatsParam{
+		Window:     d.options.Window,
+		Baseline:   d.options.Baseline,
+		MinRatings: d.options.MinRatings,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var flagged []db.Anomaly
+	for _, station := range stats {
+		evaluation := Evaluate(station, d.options)
+		if !evaluation.Anomalous {
+			continue
+		}
+
+		anomaly, created, err := d.store.FlagAnomaly(ctx, db.FlagAnomalyParam{
+			StationID:       station.StationID,
+			WindowStart:     station.WindowStart,
+			WindowEnd:       station.WindowEnd,
+			RatingCount:     evaluation.RatingCount,
+			ExpectedCount:   evaluation.ExpectedCount,
+			AverageRating:   evaluation.AverageRating,
+			BaselineAverage: evaluation.BaselineAverage,
+			Distance:        evaluation.Distance,
+			Frozen:          d.options.Freeze,
+		})
+		if err != nil {
+			return flagged, err
+		}
+		flagged = append(flagged, anomaly)
+
+		// Anomaly that is still going on was already reported.
+		if !created {
+			continue
+		}
+
+		metrics.AnomaliesDetected.Inc()
+		if err := d.publisher.Publish(ctx, events.New(EventDetected, anomaly)); err != nil {
+			zap.L().Error("Failed to publish anomaly", zap.Int64("station_id", anomaly.StationID), zap.Error(err))
+		}
+	}
+
+	return flagged, nil
+}
+
+// Compares numbers of ratings by stars in the window with the baseline. Window is
+// anomalous when it has many more ratings than expected and their stars are
+// distributed differently.
+func Evaluate(stats db.StationWindowStats, options Options) Evaluation {
+	var evaluation Evaluation
+
+	var baselineCount int64
+	for i := 0; i < 5; i++ {
+		evaluation.RatingCount += stats.WindowCounts[i]
+		baselineCount += stats.BaselineCounts[i]
+	}
+	if evaluation.RatingCount == 0 {
+		return evaluation
+	}
+
+	evaluation.ExpectedCount = float64(baselineCount) * options.Window.Seconds() / options.Baseline.Seconds()
+	evaluation.AverageRating = average(stats.WindowCounts)
+	evaluation.BaselineAverage = average(stats.BaselineCounts)
+
+	// Baseline is smoothed, so that stations without history have uniform distribution.
+	for i := 0; i < 5; i++ {
+		window := float64(stats.WindowCounts[i]) / float64(evaluation.RatingCount)
+		baseline := float64(stats.BaselineCounts[i]+1) / float64(baselineCount+5)
+		evaluation.Distance += math.Abs(window-baseline) / 2
+	}
+
+	fast := float64(evaluation.RatingCount) >= options.VelocityFactor*math.Max(evaluation.ExpectedCount, 1)
+	evaluation.Anomalous = evaluation.RatingCount >= int64(options.MinRatings) && fast && evaluation.Distance >= options.MinDistance
+
+	return evaluation
+}
+
+func average(counts []int64) float64 {
+	var sum, count int64
+	for i, n := range counts {
+		sum += int64(i+1) * n
+		count += n
+	}
+	if count == 0 {
+		return 0
+	}
+
+	return float64(sum) / float64(count)
+}
@@ -0,0 +1,70 @@
+package anomaly
+
+import (
+	"rating-service/db"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testOptions = Options{
+	Window:         time.Hour,
+	Baseline:       100 * time.Hour,
+	MinRatings:     10,
+	VelocityFactor: 5,
+	MinDistance:    0.3,
+}
+
+func TestEvaluateFloodOfOneStar(t *testing.T) {
+	evaluation := Evaluate(db.StationWindowStats{
+		WindowCounts:   []int64{30, 1, 0, 0, 1},
+		BaselineCounts: []int64{10, 10, 30, 100, 250},
+	}, testOptions)
+
+	require.True(t, evaluation.Anomalous)
+	require.Equal(t, int64(32), evaluation.RatingCount)
+	require.InDelta(t, 4, evaluation.ExpectedCount, 0.001)
+	require.InDelta(t, 1.16, evaluation.AverageRating, 0.01)
+	require.InDelta(t, 4.42, evaluation.BaselineAverage, 0.01)
+	require.Greater(t, evaluation.Distance, 0.8)
+}
+
+func TestEvaluatePopularStation(t *testing.T) {
+	// Many ratings distributed like before are not anomalous.
+	evaluation := Evaluate(db.StationWindowStats{
+		WindowCounts:   []int64{1, 1, 3, 10, 25},
+		BaselineCounts: []int64{10, 10, 30, 100, 250},
+	}, testOptions)
+
+	require.False(t, evaluation.Anomalous)
+	require.Less(t, evaluation.Distance, 0.1)
+}
+
+func TestEvaluateUsualVelocity(t *testing.T) {
+	// Different distribution alone is not anomalous when rate of ratings is usual.
+	evaluation := Evaluate(db.StationWindowStats{
+		WindowCounts:   []int64{12, 0, 0, 0, 0},
+		BaselineCounts: []int64{100, 100, 300, 500, 1000},
+	}, testOptions)
+
+	require.InDelta(t, 20, evaluation.ExpectedCount, 0.001)
+	require.False(t, evaluation.Anomalous)
+}
+
+func TestEvaluateNewStation(t *testing.T) {
+	// Station without history is compared with uniform distribution.
+	evaluation := Evaluate(db.StationWindowStats{
+		WindowCounts:   []int64{15, 0, 0, 0, 0},
+		BaselineCounts: []int64{0, 0, 0, 0, 0},
+	}, testOptions)
+
+	require.True(t, evaluation.Anomalous)
+	require.InDelta(t, 0.8, evaluation.Distance, 0.001)
+
+	evaluation = Evaluate(db.StationWindowStats{
+		WindowCounts:   []int64{5, 0, 0, 0, 0},
+		BaselineCounts: []int64{0, 0, 0, 0, 0},
+	}, testOptions)
+	require.False(t, evaluation.Anomalous)
+}
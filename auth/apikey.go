@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+const apiKeyPrefix = "rk"
+
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+// Generates a new API key in form rk_<prefix>_<secret>. Prefix identifies
+// the key and only hash of the secret is stored.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	id := make([]byte, 6)
+	secret := make([]byte, 24)
+	if _, err = rand.Read(id); err != nil {
+		return
+	}
+	if _, err = rand.Read(secret); err != nil {
+		return
+	}
+
+	prefix = hex.EncodeToString(id)
+	encoded := hex.EncodeToString(secret)
+	key = apiKeyPrefix + "_" + prefix + "_" + encoded
+	hash = HashSecret(encoded)
+
+	return
+}
+
+// Splits API key into its prefix and secret.
+func ParseAPIKey(key string) (prefix, secret string, err error) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", "", ErrInvalidAPIKey
+	}
+
+	return parts[1], parts[2], nil
+}
+
+// Secrets are random, so a fast hash is enough.
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Compares secret with stored hash in constant time.
+func VerifySecret(secret, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashSecret(secret)), []byte(hash)) == 1
+}
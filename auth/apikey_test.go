@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"rating-service/rbac"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, hash, err := GenerateAPIKey()
+	require.NoError(t, err)
+
+	parsedPrefix, secret, err := ParseAPIKey(key)
+	require.NoError(t, err)
+	require.Equal(t, prefix, parsedPrefix)
+	require.True(t, VerifySecret(secret, hash))
+	require.False(t, VerifySecret(secret+"0", hash))
+
+	key2, prefix2, _, err := GenerateAPIKey()
+	require.NoError(t, err)
+	require.NotEqual(t, key, key2)
+	require.NotEqual(t, prefix, prefix2)
+}
+
+func TestParseInvalidAPIKey(t *testing.T) {
+	for _, key := range []string{"", "rk", "rk__secret", "xx_prefix_secret", "rk_prefix_secret_extra"} {
+		_, _, err := ParseAPIKey(key)
+		require.ErrorIs(t, err, ErrInvalidAPIKey, key)
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	require.True(t, HasScope([]string{ScopeRead}, ScopeRead))
+	require.False(t, HasScope([]string{ScopeRead}, ScopeWrite))
+	require.True(t, HasScope([]string{ScopeAdmin}, ScopeWrite))
+	require.True(t, HasScope([]string{ScopeRead, ScopeWrite}, ScopeWrite))
+	require.False(t, HasScope(nil, ScopeRead))
+	require.False(t, HasScope([]string{"unknown"}, ScopeRead))
+}
+
+func TestScopePermissions(t *testing.T) {
+	read := ScopePermissions([]string{ScopeRead})
+	require.True(t, read.Has(rbac.ExportRatings))
+	require.False(t, read.Has(rbac.WriteRatings))
+
+	write := ScopePermissions([]string{ScopeWrite})
+	require.True(t, write.Has(rbac.ExportRatings))
+	require.True(t, write.Has(rbac.WriteRatings))
+	require.True(t, write.Has(rbac.WriteAnyRatings))
+	require.False(t, write.Has(rbac.ImportRatings))
+
+	admin := ScopePermissions([]string{ScopeAdmin})
+	require.True(t, admin.Has(rbac.ImportRatings))
+	require.True(t, admin.Has(rbac.ManageAPIKeys))
+
+	require.Empty(t, ScopePermissions([]string{"unknown"}))
+}
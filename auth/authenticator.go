@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"rating-service/db"
+	"rating-service/rbac"
+	"strconv"
+)
+
+// Prefix of subjects of users, followed by their ID.
+const userSubjectPrefix = "user:"
+
+// Authenticated caller.
+type Principal struct {
+	Subject     string
+	Roles       []rbac.Role
+	Permissions rbac.Permissions
+}
+
+// Reports whether principal may write ratings of user with given ID. Users may
+// only write their own ratings, unless they are granted WriteAnyRatings.
+func (p Principal) MayWriteRatingsOf(userID int64) bool {
+	return p.Permissions.Has(rbac.WriteAnyRatings) || p.Subject == userSubjectPrefix+strconv.FormatInt(userID, 10)
+}
+
+// Checks credentials of callers against stored API keys and JWT secret.
+type Authenticator struct {
+	store     *db.Store
+	jwtSecret string
+}
+
+func NewAuthenticator(store *db.Store, jwtSecret string) *Authenticator {
+	return &Authenticator{
+		store:     store,
+		jwtSecret: jwtSecret,
+	}
+}
+
+// Authenticates service with API key. Returns ErrInvalidAPIKey for unknown or revoked keys.
+func (a *Authenticator) APIKey(ctx context.Context, key string) (Principal, error) {
+	prefix, secret, err := ParseAPIKey(key)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	apiKey, err := a.store.GetAPIKeyByPrefix(ctx, prefix)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Principal{}, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return Principal{}, err
+	}
+
+	if apiKey.RevokedAt != nil || !VerifySecret(secret, apiKey.KeyHash) {
+		return Principal{}, ErrInvalidAPIKey
+	}
+
+	return Principal{
+		Subject:     "apikey:" + strconv.FormatInt(apiKey.ID, 10),
+		Permissions: ScopePermissions(apiKey.Scopes),
+	}, nil
+}
+
+// Authenticates user with JWT. Returns ErrInvalidToken for invalid tokens.
+// Users get roles from the roles claim, or the user role when it is missing.
+// Unknown roles are ignored.
+func (a *Authenticator) Token(token string) (Principal, error) {
+	claims, err := VerifyToken(token, a.jwtSecret)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	roles := []rbac.Role{rbac.RoleUser}
+	for _, role := range claims.Roles {
+		if rbac.ValidRole(rbac.Role(role)) && rbac.Role(role) != rbac.RoleUser {
+			roles = append(roles, rbac.Role(role))
+		}
+	}
+
+	return Principal{
+		Subject:     userSubjectPrefix + claims.Subject,
+		Roles:       roles,
+		Permissions: rbac.PermissionsOf(roles...),
+	}, nil
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims of user tokens.
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+}
+
+// Verifies HS256 signed user token and returns its claims.
+func VerifyToken(token, secret string) (Claims, error) {
+	if secret == "" {
+		return Claims{}, ErrInvalidToken
+	}
+
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+		}
+		return []byte(secret), nil
+	})
+	if err != nil || claims.Subject == "" {
+		return Claims{}, ErrInvalidToken
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func signToken(t *testing.T, method jwt.SigningMethod, claims jwt.Claims, secret string) string {
+	token, err := jwt.NewWithClaims(method, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+func TestVerifyToken(t *testing.T) {
+	claims := jwt.RegisteredClaims{
+		Subject:   "21",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+
+	verified, err := VerifyToken(signToken(t, jwt.SigningMethodHS256, claims, "secret"), "secret")
+	require.NoError(t, err)
+	require.Equal(t, "21", verified.Subject)
+	require.Empty(t, verified.Roles)
+
+	_, err = VerifyToken(signToken(t, jwt.SigningMethodHS256, claims, "other"), "secret")
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = VerifyToken(signToken(t, jwt.SigningMethodHS512, claims, "secret"), "secret")
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// Tokens are rejected when secret isn't configured.
+	_, err = VerifyToken(signToken(t, jwt.SigningMethodHS256, claims, ""), "")
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerifyExpiredToken(t *testing.T) {
+	claims := jwt.RegisteredClaims{
+		Subject:   "21",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+	}
+
+	_, err := VerifyToken(signToken(t, jwt.SigningMethodHS256, claims, "secret"), "secret")
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestVerifyTokenRoles(t *testing.T) {
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "21",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Roles: []string{"moderator"},
+	}
+
+	verified, err := VerifyToken(signToken(t, jwt.SigningMethodHS256, claims, "secret"), "secret")
+	require.NoError(t, err)
+	require.Equal(t, []string{"moderator"}, verified.Roles)
+}
+
+func TestMayWriteRatingsOf(t *testing.T) {
+	authenticator := NewAuthenticator(nil, "secret")
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "21",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+
+	// Users only write their own ratings.
+	user, err := authenticator.Token(signToken(t, jwt.SigningMethodHS256, claims, "secret"))
+	require.NoError(t, err)
+	require.True(t, user.MayWriteRatingsOf(21))
+	require.False(t, user.MayWriteRatingsOf(22))
+
+	claims.Roles = []string{"moderator"}
+	moderator, err := authenticator.Token(signToken(t, jwt.SigningMethodHS256, claims, "secret"))
+	require.NoError(t, err)
+	require.True(t, moderator.MayWriteRatingsOf(22))
+
+	// Services write ratings on behalf of users.
+	service := Principal{Subject: "apikey:1", Permissions: ScopePermissions([]string{ScopeWrite})}
+	require.True(t, service.MayWriteRatingsOf(22))
+}
@@ -0,0 +1,51 @@
+package auth
+
+import "rating-service/rbac"
+
+const (
+	ScopeRead  = "ratings:read"
+	ScopeWrite = "ratings:write"
+	ScopeAdmin = "ratings:admin"
+)
+
+// Scopes include all scopes with lower rank.
+var scopeRanks = map[string]int{
+	ScopeRead:  1,
+	ScopeWrite: 2,
+	ScopeAdmin: 3,
+}
+
+func ValidScope(scope string) bool {
+	_, ok := scopeRanks[scope]
+	return ok
+}
+
+// Checks if granted scopes include the required one.
+func HasScope(granted []string, required string) bool {
+	for _, scope := range granted {
+		if scopeRanks[scope] >= scopeRanks[required] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Permissions granted by each API key scope.
+var scopePermissions = map[string]rbac.Permissions{
+	ScopeRead:  {rbac.ExportRatings: true},
+	ScopeWrite: {rbac.ExportRatings: true, rbac.WriteRatings: true, rbac.WriteAnyRatings: true},
+	ScopeAdmin: rbac.PermissionsOf(rbac.RoleAdmin),
+}
+
+// Returns permissions granted by API key scopes.
+func ScopePermissions(scopes []string) rbac.Permissions {
+	permissions := rbac.Permissions{}
+	for _, scope := range scopes {
+		for permission := range scopePermissions[scope] {
+			permissions[permission] = true
+		}
+	}
+
+	return permissions
+}
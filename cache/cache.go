@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"rating-service/config"
+	"time"
+)
+
+const (
+	BackendLRU   = "lru"
+	BackendRedis = "redis"
+	BackendNone  = "none"
+
+	defaultSize = 10000
+	defaultTTL  = time.Minute
+)
+
+// Key-value cache of encoded responses. Every key has a generation that changes
+// whenever the key is deleted, so that a value read before the deletion is not
+// cached after it.
+type Cache interface {
+	// Returns cached value and whether it was found.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Returns current generation of key.
+	Generation(ctx context.Context, key string) (uint64, error)
+	// Stores value unless key was deleted since generation was read. Returns
+	// whether it was stored.
+	SetIfGeneration(ctx context.Context, key string, value []byte, ttl time.Duration, generation uint64) (bool, error)
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Creates cache backend selected in configuration. In-process LRU is used by default.
+func New(config config.Config) (Cache, error) {
+	switch config.CacheBackend {
+	case "", BackendLRU:
+		size := config.CacheSize
+		if size <= 0 {
+			size = defaultSize
+		}
+		return NewLRU(size), nil
+	case BackendRedis:
+		return NewRedis(config.RedisAddress, config.RedisPassword, config.RedisDB)
+	case BackendNone:
+		return noop{}, nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", config.CacheBackend)
+	}
+}
+
+// Cache that never stores anything.
+type noop struct{}
+
+func (noop) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, nil
+}
+
+func (noop) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return nil
+}
+
+func (noop) Generation(ctx context.Context, key string) (uint64, error) {
+	return 0, nil
+}
+
+func (noop) SetIfGeneration(ctx context.Context, key string, value []byte, ttl time.Duration, generation uint64) (bool, error) {
+	return false, nil
+}
+
+func (noop) Delete(ctx context.Context, keys ...string) error {
+	return nil
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// In-process cache that evicts least recently used entries once it is full.
+// Deleted keys are kept as tombstones with their generation, and are evicted
+// like other entries.
+type LRU struct {
+	mu         sync.Mutex
+	size       int
+	order      *list.List
+	entries    map[string]*list.Element
+	generation uint64
+}
+
+type lruEntry struct {
+	key        string
+	value      []byte
+	expiresAt  time.Time
+	deleted    bool
+	generation uint64
+}
+
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+func (c *LRU) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := element.Value.(*lruEntry)
+	if entry.deleted || time.Now().After(entry.expiresAt) {
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+func (c *LRU) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(key, value, ttl)
+	return nil
+}
+
+func (c *LRU) Generation(ctx context.Context, key string) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generationOf(key), nil
+}
+
+func (c *LRU) SetIfGeneration(ctx context.Context, key string, value []byte, ttl time.Duration, generation uint64) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.generationOf(key) != generation {
+		return false, nil
+	}
+
+	c.set(key, value, ttl)
+	return true, nil
+}
+
+func (c *LRU) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		c.generation++
+		if element, ok := c.entries[key]; ok {
+			entry := element.Value.(*lruEntry)
+			entry.value, entry.deleted, entry.generation = nil, true, c.generation
+			c.order.MoveToFront(element)
+			continue
+		}
+
+		c.push(&lruEntry{key: key, deleted: true, generation: c.generation})
+	}
+
+	return nil
+}
+
+// Returns generation of key, 0 when it has no entry. Generations of deleted keys
+// are never 0, so that a key evicted while a value is loaded is not filled.
+func (c *LRU) generationOf(key string) uint64 {
+	if element, ok := c.entries[key]; ok {
+		return element.Value.(*lruEntry).generation
+	}
+
+	return 0
+}
+
+// Stores value of key, keeping its generation.
+func (c *LRU) set(key string, value []byte, ttl time.Duration) {
+	expiresAt := time.Now().Add(ttl)
+
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value, entry.expiresAt, entry.deleted = value, expiresAt, false
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.push(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+}
+
+// Adds a new entry and evicts the least recently used one when cache is full.
+func (c *LRU) push(entry *lruEntry) {
+	c.entries[entry.key] = c.order.PushFront(entry)
+
+	if c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *LRU) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*lruEntry).key)
+}
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUEviction(t *testing.T) {
+	ctx := context.Background()
+	cache := NewLRU(2)
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, cache.Set(ctx, "b", []byte("2"), time.Minute))
+
+	// Reading "a" makes "b" least recently used.
+	value, ok, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), value)
+
+	require.NoError(t, cache.Set(ctx, "c", []byte("3"), time.Minute))
+
+	_, ok, _ = cache.Get(ctx, "b")
+	require.False(t, ok)
+	_, ok, _ = cache.Get(ctx, "a")
+	require.True(t, ok)
+	_, ok, _ = cache.Get(ctx, "c")
+	require.True(t, ok)
+}
+
+func TestLRUExpiration(t *testing.T) {
+	ctx := context.Background()
+	cache := NewLRU(2)
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("1"), -time.Second))
+
+	_, ok, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestLRUDelete(t *testing.T) {
+	ctx := context.Background()
+	cache := NewLRU(2)
+
+	require.NoError(t, cache.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, cache.Delete(ctx, "a", "missing"))
+
+	_, ok, err := cache.Get(ctx, "a")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestLRUGeneration(t *testing.T) {
+	ctx := context.Background()
+	cache := NewLRU(2)
+
+	generation, err := cache.Generation(ctx, "a")
+	require.NoError(t, err)
+
+	// Deletion while a value is loaded prevents the fill.
+	require.NoError(t, cache.Delete(ctx, "a"))
+	stored, err := cache.SetIfGeneration(ctx, "a", []byte("1"), time.Minute, generation)
+	require.NoError(t, err)
+	require.False(t, stored)
+	_, ok, _ := cache.Get(ctx, "a")
+	require.False(t, ok)
+
+	generation, err = cache.Generation(ctx, "a")
+	require.NoError(t, err)
+	stored, err = cache.SetIfGeneration(ctx, "a", []byte("1"), time.Minute, generation)
+	require.NoError(t, err)
+	require.True(t, stored)
+	_, ok, _ = cache.Get(ctx, "a")
+	require.True(t, ok)
+
+	// Tombstone evicted while a value is loaded doesn't let the fill through.
+	require.NoError(t, cache.Delete(ctx, "b"))
+	generation, err = cache.Generation(ctx, "b")
+	require.NoError(t, err)
+	require.NoError(t, cache.Delete(ctx, "c", "d"))
+	stored, err = cache.SetIfGeneration(ctx, "b", []byte("2"), time.Minute, generation)
+	require.NoError(t, err)
+	require.False(t, stored)
+
+	// Tombstones are bounded by size of the cache.
+	require.LessOrEqual(t, len(cache.entries), 2)
+}
@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"rating-service/config"
+	"rating-service/db"
+	"rating-service/logging"
+	"rating-service/metrics"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// Caches station ratings and summaries in front of the store. Entries are
+// invalidated whenever ratings of a station are written. They are filled from
+// primary, so that a lagging replica doesn't cache data from before the write, and
+// only when their generation in the backend didn't change while the query ran, so
+// that writes on any replica of the service stop stale fills.
+type Ratings struct {
+	store *db.Store
+	cache Cache
+	ttl   int64
+	group singleflight.Group
+}
+
+// Limits queries shared by concurrent callers, which don't depend on context of
+// any single caller.
+const loadTimeout = 10 * time.Second
+
+func NewRatings(config config.Config, store *db.Store) (*Ratings, error) {
+	backend, err := New(config)
+	if err != nil {
+		return nil, err
+	}
+
+	ratings := &Ratings{
+		store: store,
+		cache: backend,
+	}
+	ratings.SetTTL(config.CacheTTL)
+	store.OnWrite(ratings.Invalidate)
+
+	return ratings, nil
+}
+
+// Changes how long new entries are cached.
+func (r *Ratings) SetTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+
+	atomic.StoreInt64(&r.ttl, int64(ttl))
+}
+
+// Returns underlying cache backend.
+func (r *Ratings) Backend() Cache {
+	return r.cache
+}
+
+func (r *Ratings) GetAllByStation(ctx context.Context, stationID int64) (ratings []db.Rating, err error) {
+	err = r.load(ctx, "station_ratings", stationRatingsKey(stationID), &ratings, func(ctx context.Context) (interface{}, error) {
+		return r.store.GetAllByStation(db.WithPrimary(ctx), stationID)
+	})
+
+	return
+}
+
+func (r *Ratings) GetStationSummary(ctx context.Context, stationID int64) (summary db.StationSummary, err error) {
+	err = r.load(ctx, "station_summary", stationSummaryKey(stationID), &summary, func(ctx context.Context) (interface{}, error) {
+		return r.store.GetStationSummary(db.WithPrimary(ctx), stationID)
+	})
+
+	return
+}
+
+// Removes cached entries of given stations.
+func (r *Ratings) Invalidate(ctx context.Context, stationIDs ...int64) {
+	keys := make([]string, 0, 2*len(stationIDs))
+	for _, stationID := range stationIDs {
+		keys = append(keys, stationRatingsKey(stationID), stationSummaryKey(stationID))
+	}
+
+	// Queries that are already running may return stale results, don't share
+	// them. Deletion changes generations of keys, so they won't be cached either.
+	for _, key := range keys {
+		r.group.Forget(key)
+	}
+
+	if err := r.cache.Delete(ctx, keys...); err != nil {
+		logging.FromContext(ctx).Warn("Failed to invalidate cache", zap.Error(err))
+	}
+}
+
+// Reads cached value into dest. On a miss the query is executed once for all
+// concurrent callers and its result is cached, unless the key was invalidated
+// while the query ran.
+func (r *Ratings) load(ctx context.Context, resource, key string, dest interface{}, query func(ctx context.Context) (interface{}, error)) error {
+	data, ok, err := r.cache.Get(ctx, key)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Failed to read from cache", zap.Error(err))
+	}
+	if ok {
+		metrics.CacheLookups.WithLabelValues(resource, "hit").Inc()
+		return json.Unmarshal(data, dest)
+	}
+	metrics.CacheLookups.WithLabelValues(resource, "miss").Inc()
+
+	results := r.group.DoChan(key, func() (interface{}, error) {
+		// Cancelled caller must not fail the others waiting for the same query.
+		ctx, cancel := context.WithTimeout(detached{ctx}, loadTimeout)
+		defer cancel()
+
+		generation, err := r.cache.Generation(ctx, key)
+		if err != nil {
+			logging.FromContext(ctx).Warn("Failed to read from cache", zap.Error(err))
+		}
+		cacheable := err == nil
+
+		result, err := query(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+
+		if !cacheable {
+			return data, nil
+		}
+		if _, err := r.cache.SetIfGeneration(ctx, key, data, time.Duration(atomic.LoadInt64(&r.ttl)), generation); err != nil {
+			logging.FromContext(ctx).Warn("Failed to write to cache", zap.Error(err))
+		}
+
+		return data, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case result := <-results:
+		if result.Err != nil {
+			return result.Err
+		}
+		return json.Unmarshal(result.Val.([]byte), dest)
+	}
+}
+
+// Context that keeps values of its parent, e.g. logger and span, but is never
+// cancelled with it.
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detached) Done() <-chan struct{}               { return nil }
+func (detached) Err() error                          { return nil }
+func (d detached) Value(key interface{}) interface{} { return d.parent.Value(key) }
+
+func stationRatingsKey(stationID int64) string {
+	return fmt.Sprintf("rating-service:station:%d:ratings", stationID)
+}
+
+func stationSummaryKey(stationID int64) string {
+	return fmt.Sprintf("rating-service:station:%d:summary", stationID)
+}
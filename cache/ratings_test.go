@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRatings() *Ratings {
+	return &Ratings{cache: NewLRU(10), ttl: int64(time.Minute)}
+}
+
+func TestLoadSkipsInvalidatedResult(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRatings()
+	key := stationSummaryKey(1)
+
+	// Write invalidates the station while the query still reads old data.
+	var value int
+	err := r.load(ctx, "station_summary", key, &value, func(ctx context.Context) (interface{}, error) {
+		r.Invalidate(ctx, 1)
+		return 1, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, value)
+
+	_, ok, _ := r.cache.Get(ctx, key)
+	require.False(t, ok)
+
+	// Next query result is cached.
+	err = r.load(ctx, "station_summary", key, &value, func(ctx context.Context) (interface{}, error) {
+		return 2, nil
+	})
+	require.NoError(t, err)
+
+	data, ok, _ := r.cache.Get(ctx, key)
+	require.True(t, ok)
+	require.Equal(t, []byte("2"), data)
+}
+
+func TestLoadIgnoresCancelledCaller(t *testing.T) {
+	r := newTestRatings()
+	key := stationSummaryKey(2)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	query := func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-release
+		return 3, ctx.Err()
+	}
+
+	// First caller gives up while the query runs.
+	cancelled, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		var value int
+		errs <- r.load(cancelled, "station_summary", key, &value, query)
+	}()
+	<-started
+	cancel()
+	require.ErrorIs(t, <-errs, context.Canceled)
+
+	// Query keeps running for other callers and its result is cached.
+	close(release)
+	require.Eventually(t, func() bool {
+		_, ok, _ := r.cache.Get(context.Background(), key)
+		return ok
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// Counter that numbers generations of all keys, so that a generation is never
+	// reused, even after it expired.
+	generationCounterKey = "rating-service:cache:generation"
+	// Generations only need to outlive values that are being loaded.
+	generationTTL = time.Hour
+)
+
+// Stores value in KEYS[1] when generation in KEYS[2] is still ARGV[3].
+var setIfGenerationScript = redis.NewScript(`
+if (redis.call("GET", KEYS[2]) or "0") ~= ARGV[3] then
+	return 0
+end
+
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return 1
+`)
+
+// Deletes keys, listed first in KEYS, and gives each of them, listed next, a new
+// generation taken from the counter in the last key.
+var deleteScript = redis.NewScript(`
+local count = (#KEYS - 1) / 2
+local generation = redis.call("INCR", KEYS[#KEYS])
+
+for i = 1, count do
+	redis.call("DEL", KEYS[i])
+	redis.call("SET", KEYS[count + i], generation, "PX", ARGV[1])
+end
+
+return generation
+`)
+
+// Cache shared by all replicas of the service.
+type Redis struct {
+	client *redis.Client
+}
+
+func NewRedis(address, password string, db int) (*Redis, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     address,
+		Password: password,
+		DB:       db,
+	})
+
+	return &Redis{client: client}, nil
+}
+
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+func (c *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (c *Redis) Generation(ctx context.Context, key string) (uint64, error) {
+	generation, err := c.client.Get(ctx, generationKey(key)).Uint64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+
+	return generation, err
+}
+
+func (c *Redis) SetIfGeneration(ctx context.Context, key string, value []byte, ttl time.Duration, generation uint64) (bool, error) {
+	return setIfGenerationScript.Run(ctx, c.client, []string{key, generationKey(key)}, value, milliseconds(ttl), generation).Bool()
+}
+
+func (c *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	scriptKeys := make([]string, 0, 2*len(keys)+1)
+	scriptKeys = append(scriptKeys, keys...)
+	for _, key := range keys {
+		scriptKeys = append(scriptKeys, generationKey(key))
+	}
+	scriptKeys = append(scriptKeys, generationCounterKey)
+
+	return deleteScript.Run(ctx, c.client, scriptKeys, milliseconds(generationTTL)).Err()
+}
+
+func (c *Redis) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+func generationKey(key string) string {
+	return key + ":generation"
+}
+
+// Converts ttl to milliseconds for PX, which must be positive.
+func milliseconds(ttl time.Duration) int64 {
+	if ms := ttl.Milliseconds(); ms > 0 {
+		return ms
+	}
+	return 1
+}
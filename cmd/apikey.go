@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rating-service/auth"
+	"rating-service/db"
+
+	"github.com/spf13/cobra"
+)
+
+var apiKeyCmd = &cobra.Command{
+	Use:   "apikey",
+	Short: "Manage API keys",
+}
+
+var apiKeyCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create an API key, e.g. to bootstrap access to admin endpoints",
+	Args:  cobra.NoArgs,
+	RunE:  runAPIKeyCreate,
+}
+
+func init() {
+	apiKeyCreateCmd.Flags().String("name", "", "name of the API key")
+	apiKeyCreateCmd.Flags().StringSlice("scopes", []string{auth.ScopeRead}, "comma separated list of scopes")
+
+	apiKeyCmd.AddCommand(apiKeyCreateCmd)
+	rootCmd.AddCommand(apiKeyCmd)
+}
+
+func runAPIKeyCreate(cmd *cobra.Command, args []string) error {
+	name, _ := cmd.Flags().GetString("name")
+	scopes, _ := cmd.Flags().GetStringSlice("scopes")
+
+	if name == "" {
+		return errors.New("--name is required")
+	}
+
+	arg := db.CreateAPIKeyParam{
+		Name:   name,
+		Scopes: scopes,
+	}
+	for _, scope := range arg.Scopes {
+		if !auth.ValidScope(scope) {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return err
+	}
+	arg.Prefix = prefix
+	arg.KeyHash = hash
+
+	store, err := connect()
+	if err != nil {
+		return err
+	}
+
+	apiKey, err := store.CreateAPIKey(context.Background(), arg)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(db.IssuedAPIKey{APIKey: apiKey, Key: key})
+}
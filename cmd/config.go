@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"rating-service/config"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect configuration",
+
+	// Configuration is loaded by subcommands, so that invalid one can be inspected.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+}
+
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Print effective configuration after applying defaults, config file and environment",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigPrint,
+}
+
+func init() {
+	configPrintCmd.Flags().Bool("redacted", false, "hide secrets, such as passwords and JWT secret")
+
+	configCmd.AddCommand(configPrintCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// Prints configuration and then reports problems with it, if any.
+func runConfigPrint(cmd *cobra.Command, args []string) error {
+	redacted, _ := cmd.Flags().GetBool("redacted")
+
+	settings, err := config.Read(configPath)
+	if err != nil {
+		return err
+	}
+
+	if err := printJSON(settings.Map(redacted)); err != nil {
+		return err
+	}
+
+	return settings.Validate()
+}
@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"rating-service/cache"
+	"rating-service/db"
+	"rating-service/moderation"
+	"rating-service/seed"
+	"rating-service/similarity"
+
+	"github.com/spf13/cobra"
+)
+
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Populate database with realistic generated ratings and comments",
+	Args:  cobra.NoArgs,
+	RunE:  runSeed,
+}
+
+var recomputeStatsCmd = &cobra.Command{
+	Use:   "recompute-stats",
+	Short: "Recompute station summaries and refresh cached ones",
+	Args:  cobra.NoArgs,
+	RunE:  runRecomputeStats,
+}
+
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Delete ratings of a station or user, or ratings older than given time",
+	Args:  cobra.NoArgs,
+	RunE:  runPurge,
+}
+
+var backfillSentimentCmd = &cobra.Command{
+	Use:   "backfill-sentiment",
+	Short: "Score sentiment of comments of existing ratings",
+	Args:  cobra.NoArgs,
+	RunE:  runBackfillSentiment,
+}
+
+var backfillSignaturesCmd = &cobra.Command{
+	Use:   "backfill-signatures",
+	Short: "Store signatures of comments of existing ratings for duplicate detection",
+	Args:  cobra.NoArgs,
+	RunE:  runBackfillSignatures,
+}
+
+func init() {
+	seedCmd.Flags().Int64("seed", 1, "seed of the generator, same seed generates the same ratings")
+	seedCmd.Flags().Int("ratings", 1000, "number of ratings")
+	seedCmd.Flags().Int("stations", 50, "number of stations")
+	seedCmd.Flags().Int("users", 500, "number of users")
+	seedCmd.Flags().Int("months", 6, "number of months ratings are spread over")
+	seedCmd.Flags().String("end", "", "time of the latest rating (RFC 3339, defaults to 2022-01-01T00:00:00Z)")
+	seedCmd.Flags().Bool("dry-run", false, "validate generated ratings and roll back without importing")
+
+	recomputeStatsCmd.Flags().Int64Slice("station-id", nil, "recompute only given stations (defaults to all stations)")
+
+	purgeCmd.Flags().Int64("station-id", 0, "delete ratings of given station")
+	purgeCmd.Flags().Int64("user-id", 0, "delete ratings of given user")
+	purgeCmd.Flags().String("before", "", "delete ratings created before given time (RFC 3339)")
+	purgeCmd.Flags().Bool("dry-run", false, "count matching ratings and roll back without deleting")
+
+	backfillSentimentCmd.Flags().Bool("all", false, "score all ratings again (defaults to ratings without sentiment)")
+	backfillSentimentCmd.Flags().Int("batch-size", 1000, "number of ratings scored in a single query")
+
+	backfillSignaturesCmd.Flags().Bool("all", false, "sign all comments again (defaults to comments without signature)")
+	backfillSignaturesCmd.Flags().Int("batch-size", 1000, "number of comments signed in a single query")
+
+	rootCmd.AddCommand(seedCmd, recomputeStatsCmd, purgeCmd, backfillSentimentCmd, backfillSignaturesCmd)
+}
+
+func runSeed(cmd *cobra.Command, args []string) error {
+	options := seed.Options{}
+	options.Seed, _ = cmd.Flags().GetInt64("seed")
+	options.Ratings, _ = cmd.Flags().GetInt("ratings")
+	options.Stations, _ = cmd.Flags().GetInt("stations")
+	options.Users, _ = cmd.Flags().GetInt("users")
+	options.Months, _ = cmd.Flags().GetInt("months")
+	end, _ := cmd.Flags().GetString("end")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	var err error
+	if options.End, err = parseTimeFlag("end", end); err != nil {
+		return err
+	}
+
+	store, err := connect()
+	if err != nil {
+		return err
+	}
+
+	// Generated comments are built from a few sentences, so they are not
+	// checked for duplicates of each other.
+	store.SetDuplicateDetection(similarity.Options{Action: moderation.ActionNone})
+
+	// Generated ratings are imported with COPY.
+	report, err := store.Import(context.Background(), &seedSource{generator: seed.New(options)}, dryRun)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(report)
+}
+
+// Feeds generated ratings to import.
+type seedSource struct {
+	generator *seed.Generator
+	row       int
+}
+
+func (s *seedSource) Next() (db.ImportRecord, error) {
+	rating, ok := s.generator.Next()
+	if !ok {
+		return db.ImportRecord{}, io.EOF
+	}
+	s.row++
+
+	return db.ImportRecord{
+		Row: s.row,
+		Rating: db.ImportRatingParam{
+			Station_id: rating.StationID,
+			User_id:    rating.UserID,
+			Rating:     rating.Stars,
+			Comment:    rating.Comment,
+			CreatedAt:  rating.CreatedAt,
+		},
+	}, nil
+}
+
+func runRecomputeStats(cmd *cobra.Command, args []string) error {
+	stationIDs, _ := cmd.Flags().GetInt64Slice("station-id")
+
+	store, err := connect()
+	if err != nil {
+		return err
+	}
+
+	ratings, err := cache.NewRatings(settings, store)
+	if err != nil {
+		return err
+	}
+
+	// Summaries are computed from primary, which doesn't lag behind.
+	ctx := db.WithPrimary(context.Background())
+	if len(stationIDs) == 0 {
+		if stationIDs, err = store.StationIDs(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Drop cached entries, so that summaries are computed again and cached.
+	ratings.Invalidate(ctx, stationIDs...)
+
+	summaries := make([]db.StationSummary, 0, len(stationIDs))
+	for _, stationID := range stationIDs {
+		summary, err := ratings.GetStationSummary(ctx, stationID)
+		if err != nil {
+			return err
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return printJSON(summaries)
+}
+
+func runPurge(cmd *cobra.Command, args []string) error {
+	stationID, _ := cmd.Flags().GetInt64("station-id")
+	userID, _ := cmd.Flags().GetInt64("user-id")
+	before, _ := cmd.Flags().GetString("before")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	arg := db.PurgeParam{
+		StationID: stationID,
+		UserID:    userID,
+		DryRun:    dryRun,
+	}
+
+	var err error
+	if arg.Before, err = parseTimeFlag("before", before); err != nil {
+		return err
+	}
+
+	store, err := connect()
+	if err != nil {
+		return err
+	}
+
+	// Cached ratings of purged stations are invalidated by the store.
+	if _, err := cache.NewRatings(settings, store); err != nil {
+		return err
+	}
+
+	report, err := store.Purge(context.Background(), arg)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(report)
+}
+
+func runBackfillSentiment(cmd *cobra.Command, args []string) error {
+	arg := db.BackfillSentimentParam{}
+	arg.All, _ = cmd.Flags().GetBool("all")
+	arg.BatchSize, _ = cmd.Flags().GetInt("batch-size")
+	if arg.BatchSize < 1 {
+		return fmt.Errorf("invalid --batch-size %d", arg.BatchSize)
+	}
+
+	store, err := connect()
+	if err != nil {
+		return err
+	}
+
+	// Cached summaries of changed stations are invalidated by the store.
+	if _, err := cache.NewRatings(settings, store); err != nil {
+		return err
+	}
+
+	report, err := store.BackfillSentiment(context.Background(), arg)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(report)
+}
+
+func runBackfillSignatures(cmd *cobra.Command, args []string) error {
+	arg := db.BackfillSignaturesParam{}
+	arg.All, _ = cmd.Flags().GetBool("all")
+	arg.BatchSize, _ = cmd.Flags().GetInt("batch-size")
+	if arg.BatchSize < 1 {
+		return fmt.Errorf("invalid --batch-size %d", arg.BatchSize)
+	}
+
+	store, err := connect()
+	if err != nil {
+		return err
+	}
+
+	report, err := store.BackfillSignatures(context.Background(), arg)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(report)
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"rating-service/db"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply or revert embedded database migrations",
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply all pending migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrator(func(migrator *db.Migrator) error {
+			return migrator.Up()
+		})
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Revert applied migrations",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps, _ := cmd.Flags().GetInt("steps")
+		if steps < 1 {
+			return fmt.Errorf("invalid --steps %d", steps)
+		}
+
+		return withMigrator(func(migrator *db.Migrator) error {
+			return migrator.Down(steps)
+		})
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print version of database schema",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withMigrator(func(migrator *db.Migrator) error {
+			return nil
+		})
+	},
+}
+
+func init() {
+	migrateDownCmd.Flags().Int("steps", 1, "number of migrations to revert")
+
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateStatusCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
+
+// Runs fn with a migrator and prints status of schema afterwards.
+func withMigrator(fn func(migrator *db.Migrator) error) error {
+	migrator, err := db.NewMigrator(settings.DBSource)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	if err := fn(migrator); err != nil {
+		return err
+	}
+
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	return printJSON(status)
+}
+
+// Applies pending migrations when the server starts.
+func migrateUp() error {
+	migrator, err := db.NewMigrator(settings.DBSource)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	if err := migrator.Up(); err != nil {
+		return err
+	}
+
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	zap.L().Info("Database schema is up to date", zap.Int64("version", status.Version))
+	return nil
+}
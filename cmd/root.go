@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"rating-service/config"
+	"rating-service/db"
+	"rating-service/logging"
+	"rating-service/moderation"
+	"rating-service/similarity"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Directory with configuration file.
+	configPath string
+
+	// Configuration shared by all commands.
+	settings config.Config
+)
+
+var rootCmd = &cobra.Command{
+	Use:               "rating-service",
+	Short:             "Microservice for ratings and comments of charging stations",
+	Long:              "Microservice for ratings and comments of charging stations. Starts the server when no command is given.",
+	SilenceUsage:      true,
+	PersistentPreRunE: setup,
+	RunE:              runServe,
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", ".", "directory with config file (config.json, config.yaml or config.toml)")
+}
+
+// Runs command given in arguments.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// Loads configuration and sets up logger before any command runs.
+func setup(cmd *cobra.Command, args []string) (err error) {
+	settings, err = config.New(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if _, err = logging.New(settings.LogLevel); err != nil {
+		return fmt.Errorf("failed to create a logger: %w", err)
+	}
+
+	return nil
+}
+
+// Connects to the database. Comments written by any command are checked by
+// content filters and duplicate detection.
+func connect() (*db.Store, error) {
+	pipeline, err := moderation.New(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create content filters: %w", err)
+	}
+
+	store, err := db.Open(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	store.SetContentFilter(pipeline)
+	store.SetDuplicateDetection(similarity.OptionsFrom(settings))
+
+	return store, nil
+}
+
+// Prints value as indented JSON to standard output.
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func parseTimeFlag(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s: %w", name, err)
+	}
+
+	return t, nil
+}
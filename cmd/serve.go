@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"rating-service/anomaly"
+	"rating-service/cache"
+	"rating-service/config"
+	"rating-service/events"
+	"rating-service/gapi"
+	"rating-service/logging"
+	"rating-service/metrics"
+	"rating-service/server"
+	"rating-service/tracing"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Start HTTP and gRPC servers",
+	Args:  cobra.NoArgs,
+	RunE:  runServe,
+}
+
+// Time active requests get to finish when servers are stopped.
+const shutdownTimeout = 15 * time.Second
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+
+	// Connect to the database and close it once servers are stopped.
+	store, err := connect()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	// Apply pending migrations before serving requests.
+	if settings.AutoMigrate {
+		if err := migrateUp(); err != nil {
+			return fmt.Errorf("failed to migrate database: %w", err)
+		}
+	}
+
+	// Export connection pool statistics.
+	if err := store.RegisterMetrics(metrics.Registry); err != nil {
+		return fmt.Errorf("failed to register metrics: %w", err)
+	}
+
+	// Setup tracing and flush remaining spans on exit.
+	shutdown, err := tracing.Setup(context.Background(), settings)
+	if err != nil {
+		return fmt.Errorf("failed to setup tracing: %w", err)
+	}
+	defer shutdown(context.Background())
+
+	// Servers and background jobs stop on interrupt or when either server fails.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	group, ctx := errgroup.WithContext(ctx)
+
+	// Setup cache of station ratings.
+	ratings, err := cache.NewRatings(settings, store)
+	if err != nil {
+		return fmt.Errorf("failed to create a cache: %w", err)
+	}
+
+	// Watch stations for floods of unusual ratings.
+	alerts, err := events.NewPublisher(settings)
+	if err != nil {
+		return fmt.Errorf("failed to create alert publisher: %w", err)
+	}
+	if settings.AnomalyDetection {
+		detector := anomaly.NewDetector(store, alerts, anomaly.OptionsFrom(settings))
+		go detector.Run(ctx)
+	}
+
+	// Apply changes of reloadable settings to running components.
+	live := config.NewLive(configPath, settings)
+	live.OnChange(func(settings config.Config) {
+		if err := logging.SetLevel(settings.LogLevel); err != nil {
+			zap.L().Error("Failed to change log level", zap.Error(err))
+		}
+		ratings.SetTTL(settings.CacheTTL)
+	})
+	if err := live.Watch(); err != nil {
+		return fmt.Errorf("failed to watch config file: %w", err)
+	}
+
+	// Create servers and setup routes.
+	httpServer, err := server.NewServer(live, store, ratings, alerts)
+	if err != nil {
+		return fmt.Errorf("failed to create a server: %w", err)
+	}
+	grpcServer, err := gapi.NewServer(settings, store, ratings)
+	if err != nil {
+		return fmt.Errorf("failed to create a gRPC server: %w", err)
+	}
+
+	// Delete expired idempotency keys in the background.
+	go httpServer.CleanupIdempotencyKeys(ctx)
+
+	// Start servers.
+	group.Go(func() error {
+		zap.L().Info("Starting HTTP server", zap.String("address", settings.ServerAddress))
+		if err := httpServer.Start(settings.ServerAddress); err != nil {
+			return fmt.Errorf("failed to start a server: %w", err)
+		}
+		return nil
+	})
+	group.Go(func() error {
+		zap.L().Info("Starting gRPC server", zap.String("address", settings.GRPCServerAddress))
+		if err := grpcServer.Start(settings.GRPCServerAddress); err != nil {
+			return fmt.Errorf("failed to start a gRPC server: %w", err)
+		}
+		return nil
+	})
+
+	// Stop both servers together, letting active requests finish.
+	group.Go(func() error {
+		<-ctx.Done()
+		zap.L().Info("Stopping servers")
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		grpcServer.Shutdown(ctx)
+		return httpServer.Shutdown(ctx)
+	})
+
+	return group.Wait()
+}
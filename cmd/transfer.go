@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"rating-service/db"
+	"rating-service/transfer"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import ratings from CSV or NDJSON file, use - to read from standard input",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runImport,
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "Export ratings to CSV, NDJSON or Parquet file, use - to write to standard output",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runExport,
+}
+
+func init() {
+	importCmd.Flags().String("format", "", "format of input file, csv or ndjson (defaults to file extension)")
+	importCmd.Flags().Bool("dry-run", false, "validate rows and roll back without importing")
+
+	exportCmd.Flags().String("format", "", "format of output file, csv, ndjson or parquet (defaults to file extension)")
+	exportCmd.Flags().Int64("station-id", 0, "export only ratings of given station")
+	exportCmd.Flags().String("from", "", "export only ratings created at or after given time (RFC 3339)")
+	exportCmd.Flags().String("to", "", "export only ratings created before given time (RFC 3339)")
+
+	rootCmd.AddCommand(importCmd, exportCmd)
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	path := args[0]
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	source, err := transfer.NewReader(format, input)
+	if err != nil {
+		return err
+	}
+
+	store, err := connect()
+	if err != nil {
+		return err
+	}
+
+	// Execute import and print report.
+	report, err := store.Import(context.Background(), source, dryRun)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(report)
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	stationID, _ := cmd.Flags().GetInt64("station-id")
+	from, _ := cmd.Flags().GetString("from")
+	to, _ := cmd.Flags().GetString("to")
+
+	path := args[0]
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+
+	filter := db.RatingFilter{StationID: stationID}
+	var err error
+	if filter.From, err = parseTimeFlag("from", from); err != nil {
+		return err
+	}
+	if filter.To, err = parseTimeFlag("to", to); err != nil {
+		return err
+	}
+
+	store, err := connect()
+	if err != nil {
+		return err
+	}
+
+	var output io.Writer = os.Stdout
+	if path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		output = file
+	}
+
+	writer, err := transfer.NewWriter(format, output)
+	if err != nil {
+		return err
+	}
+
+	// Stream rows into the file.
+	count := 0
+	err = store.Export(context.Background(), filter, func(rating db.Rating) error {
+		count++
+		return writer.Write(rating)
+	})
+	if err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	zap.L().Info("Exported ratings", zap.Int("count", count))
+	return nil
+}
@@ -1,30 +1,206 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	HostAddress   string `mapstructure:"host_address"`
-	DBDriver      string `mapstructure:"db_driver"`
-	DBSource      string `mapstructure:"db_source"`
-	ServerAddress string `mapstructure:"server_address"`
-	GinMode       string `mapstructure:"gin_mode"`
+	HostAddress                   string        `mapstructure:"host_address"`
+	DBDriver                      string        `mapstructure:"db_driver"`
+	DBSource                      string        `mapstructure:"db_source" secret:"true"`
+	DBReplicaSources              []string      `mapstructure:"db_replica_sources" secret:"true"`
+	DBMaxOpenConns                int           `mapstructure:"db_max_open_conns"`
+	DBMaxIdleConns                int           `mapstructure:"db_max_idle_conns"`
+	DBConnMaxLifetime             time.Duration `mapstructure:"db_conn_max_lifetime"`
+	DBConnMaxIdleTime             time.Duration `mapstructure:"db_conn_max_idle_time"`
+	DBReplicaCheckInterval        time.Duration `mapstructure:"db_replica_check_interval"`
+	AutoMigrate                   bool          `mapstructure:"auto_migrate"`
+	ServerAddress                 string        `mapstructure:"server_address"`
+	GRPCServerAddress             string        `mapstructure:"grpc_server_address"`
+	GinMode                       string        `mapstructure:"gin_mode"`
+	TrustedProxies                []string      `mapstructure:"trusted_proxies"`
+	LogLevel                      string        `mapstructure:"log_level"`
+	IdempotencyTTL                time.Duration `mapstructure:"idempotency_ttl"`
+	IdempotencyLease              time.Duration `mapstructure:"idempotency_lease"`
+	CacheBackend                  string        `mapstructure:"cache_backend"`
+	CacheSize                     int           `mapstructure:"cache_size"`
+	CacheTTL                      time.Duration `mapstructure:"cache_ttl"`
+	RedisAddress                  string        `mapstructure:"redis_address"`
+	RedisPassword                 string        `mapstructure:"redis_password" secret:"true"`
+	RedisDB                       int           `mapstructure:"redis_db"`
+	RateLimitStore                string        `mapstructure:"rate_limit_store"`
+	RateLimitReadRate             float64       `mapstructure:"rate_limit_read_rate"`
+	RateLimitReadBurst            int           `mapstructure:"rate_limit_read_burst"`
+	RateLimitWriteRate            float64       `mapstructure:"rate_limit_write_rate"`
+	RateLimitWriteBurst           int           `mapstructure:"rate_limit_write_burst"`
+	JWTSecret                     string        `mapstructure:"jwt_secret" secret:"true"`
+	TracingExporter               string        `mapstructure:"tracing_exporter"`
+	OTLPEndpoint                  string        `mapstructure:"otlp_endpoint"`
+	TracingSampleRatio            float64       `mapstructure:"tracing_sample_ratio"`
+	ModerationWordlistDir         string        `mapstructure:"moderation_wordlist_dir"`
+	ModerationProfanityAction     string        `mapstructure:"moderation_profanity_action"`
+	ModerationLinkAction          string        `mapstructure:"moderation_link_action"`
+	ModerationPhoneAction         string        `mapstructure:"moderation_phone_action"`
+	ModerationMaxRepeatedChars    int           `mapstructure:"moderation_max_repeated_chars"`
+	ModerationRepeatedCharsAction string        `mapstructure:"moderation_repeated_chars_action"`
+	ModerationBlocklist           []string      `mapstructure:"moderation_blocklist"`
+	ModerationBlocklistAction     string        `mapstructure:"moderation_blocklist_action"`
+	AnomalyDetection              bool          `mapstructure:"anomaly_detection"`
+	AnomalyInterval               time.Duration `mapstructure:"anomaly_interval"`
+	AnomalyWindow                 time.Duration `mapstructure:"anomaly_window"`
+	AnomalyBaseline               time.Duration `mapstructure:"anomaly_baseline"`
+	AnomalyMinRatings             int           `mapstructure:"anomaly_min_ratings"`
+	AnomalyVelocityFactor         float64       `mapstructure:"anomaly_velocity_factor"`
+	AnomalyMinDistance            float64       `mapstructure:"anomaly_min_distance"`
+	AnomalyFreeze                 bool          `mapstructure:"anomaly_freeze"`
+	AlertPublisher                string        `mapstructure:"alert_publisher"`
+	AlertWebhookURL               string        `mapstructure:"alert_webhook_url" secret:"true"`
+	AlertChannel                  string        `mapstructure:"alert_channel"`
+	DuplicateAction               string        `mapstructure:"duplicate_action"`
+	DuplicateThreshold            float64       `mapstructure:"duplicate_threshold"`
+	DuplicateMinMatches           int           `mapstructure:"duplicate_min_matches"`
+	DuplicateWindow               time.Duration `mapstructure:"duplicate_window"`
+	DuplicateMinLength            int           `mapstructure:"duplicate_min_length"`
+}
+
+// Returns configuration with default values of all optional settings.
+func Default() Config {
+	return Config{
+		HostAddress:                   "localhost:8080",
+		DBDriver:                      "postgres",
+		DBReplicaSources:              []string{},
+		DBMaxOpenConns:                25,
+		DBMaxIdleConns:                10,
+		DBConnMaxLifetime:             30 * time.Minute,
+		DBConnMaxIdleTime:             5 * time.Minute,
+		DBReplicaCheckInterval:        5 * time.Second,
+		ServerAddress:                 "0.0.0.0:8080",
+		GRPCServerAddress:             "0.0.0.0:9090",
+		GinMode:                       "release",
+		TrustedProxies:                []string{},
+		LogLevel:                      "info",
+		IdempotencyTTL:                24 * time.Hour,
+		IdempotencyLease:              time.Minute,
+		CacheBackend:                  "lru",
+		CacheSize:                     10000,
+		CacheTTL:                      time.Minute,
+		RateLimitStore:                "memory",
+		RateLimitReadRate:             20,
+		RateLimitReadBurst:            40,
+		RateLimitWriteRate:            1,
+		RateLimitWriteBurst:           10,
+		TracingExporter:               "none",
+		TracingSampleRatio:            1,
+		ModerationProfanityAction:     "mask",
+		ModerationLinkAction:          "moderate",
+		ModerationPhoneAction:         "mask",
+		ModerationMaxRepeatedChars:    5,
+		ModerationRepeatedCharsAction: "moderate",
+		ModerationBlocklist:           []string{},
+		ModerationBlocklistAction:     "reject",
+		AnomalyDetection:              true,
+		AnomalyInterval:               5 * time.Minute,
+		AnomalyWindow:                 time.Hour,
+		AnomalyBaseline:               28 * 24 * time.Hour,
+		AnomalyMinRatings:             10,
+		AnomalyVelocityFactor:         5,
+		AnomalyMinDistance:            0.3,
+		AlertPublisher:                "log",
+		AlertChannel:                  "rating-service:alerts",
+		DuplicateAction:               "moderate",
+		DuplicateThreshold:            0.8,
+		DuplicateMinMatches:           5,
+		DuplicateWindow:               24 * time.Hour,
+		DuplicateMinLength:            20,
+	}
 }
 
-// Reads configuration from file or environment variables.
+// Reads and validates configuration, see Read.
 func New(path string) (config Config, err error) {
+	if config, err = Read(path); err != nil {
+		return
+	}
 
-	viper.AddConfigPath(path)
-	viper.SetConfigName("config")
-	viper.SetConfigType("json")
-	viper.AutomaticEnv()
+	err = config.Validate()
+	return
+}
 
-	if err = viper.ReadInConfig(); err != nil {
-		return
+// Reads configuration from defaults, config file (config.json, config.yaml or
+// config.toml in given directory) and environment variables, each overriding the
+// previous one. Environment variables are upper case keys, e.g. DB_SOURCE. A
+// variable with _FILE suffix, e.g. DB_SOURCE_FILE, names a file with the value,
+// which is how Kubernetes mounts secrets. Lists are comma separated in environment
+// variables. Config file is optional.
+func Read(path string) (config Config, err error) {
+	v := viper.New()
+	v.AddConfigPath(path)
+	v.SetConfigName("config")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	// Unmarshal only sees keys viper knows about, so register all of them.
+	defaults := Default()
+	for _, field := range fields(&defaults) {
+		v.SetDefault(field.key, field.value.Interface())
+		if err = v.BindEnv(field.key); err != nil {
+			return
+		}
 	}
 
-	err = viper.Unmarshal(&config)
+	if err = v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return config, fmt.Errorf("failed to read config file: %w", err)
+		}
+		err = nil
+	}
 
+	// Secrets from files override all other sources.
+	for _, field := range fields(&defaults) {
+		name := strings.ToUpper(field.key) + "_FILE"
+		file := os.Getenv(name)
+		if file == "" {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return config, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		v.Set(field.key, strings.TrimRight(string(data), "\r\n"))
+	}
+
+	err = v.Unmarshal(&config)
 	return
 }
+
+// Setting of configuration.
+type field struct {
+	key    string
+	secret bool
+	value  reflect.Value
+}
+
+func fields(config *Config) []field {
+	value := reflect.ValueOf(config).Elem()
+
+	var result []field
+	for i := 0; i < value.NumField(); i++ {
+		tag := value.Type().Field(i).Tag
+		result = append(result, field{
+			key:    tag.Get("mapstructure"),
+			secret: tag.Get("secret") == "true",
+			value:  value.Field(i),
+		})
+	}
+
+	return result
+}
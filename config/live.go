@@ -0,0 +1,131 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Settings that are applied without restart when config file changes.
+var reloadable = map[string]bool{
+	"log_level":              true,
+	"idempotency_ttl":        true,
+	"idempotency_lease":      true,
+	"cache_ttl":              true,
+	"rate_limit_read_rate":   true,
+	"rate_limit_read_burst":  true,
+	"rate_limit_write_rate":  true,
+	"rate_limit_write_burst": true,
+}
+
+// Holds current configuration and reloads it when config file changes. Only
+// reloadable settings are replaced, all of them at once, and only when the new
+// configuration is valid. Safe for concurrent use.
+type Live struct {
+	path  string
+	value atomic.Value
+
+	// Serializes reloads.
+	mu        sync.Mutex
+	listeners []func(Config)
+}
+
+func NewLive(path string, config Config) *Live {
+	live := &Live{path: path}
+	live.value.Store(config)
+	return live
+}
+
+// Returns current configuration.
+func (l *Live) Load() Config {
+	return l.value.Load().(Config)
+}
+
+// Registers a function that is called with new configuration after it was applied.
+func (l *Live) OnChange(fn func(Config)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.listeners = append(l.listeners, fn)
+}
+
+// Starts watching config file. Does nothing when there is no config file.
+func (l *Live) Watch() error {
+	v := viper.New()
+	v.AddConfigPath(l.path)
+	v.SetConfigName("config")
+
+	if err := v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if errors.As(err, &notFound) {
+			return nil
+		}
+		return err
+	}
+
+	v.OnConfigChange(func(event fsnotify.Event) {
+		if err := l.Reload(); err != nil {
+			zap.L().Error("Rejected configuration change, keeping current configuration", zap.String("file", event.Name), zap.Error(err))
+		}
+	})
+	v.WatchConfig()
+
+	return nil
+}
+
+// Reads configuration again and applies changed reloadable settings. Current
+// configuration is kept when the new one is invalid.
+func (l *Live) Reload() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	next, err := New(l.path)
+	if err != nil {
+		return err
+	}
+
+	current := l.Load()
+	updated := current
+
+	applied := make(map[string]string)
+	var ignored []string
+
+	currentFields := fields(&current)
+	nextFields := fields(&next)
+	updatedFields := fields(&updated)
+
+	for i, field := range currentFields {
+		if reflect.DeepEqual(field.value.Interface(), nextFields[i].value.Interface()) {
+			continue
+		}
+
+		if !reloadable[field.key] {
+			ignored = append(ignored, field.key)
+			continue
+		}
+
+		updatedFields[i].value.Set(nextFields[i].value)
+		applied[field.key] = fmt.Sprintf("%v -> %v", field.value.Interface(), nextFields[i].value.Interface())
+	}
+
+	if len(ignored) > 0 {
+		zap.L().Warn("Changed settings are applied only after restart", zap.Strings("settings", ignored))
+	}
+	if len(applied) == 0 {
+		return nil
+	}
+
+	l.value.Store(updated)
+	for _, listener := range l.listeners {
+		listener(updated)
+	}
+
+	zap.L().Info("Applied configuration changes", zap.Any("changes", applied))
+	return nil
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveReload(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "config.json", `{"db_source": "`+testSource+`", "log_level": "info"}`)
+
+	config, err := New(dir)
+	require.NoError(t, err)
+
+	live := NewLive(dir, config)
+
+	var notified []Config
+	live.OnChange(func(config Config) {
+		notified = append(notified, config)
+	})
+
+	// Only reloadable settings are applied.
+	writeFile(t, dir, "config.json", `{"db_source": "postgres://other", "log_level": "debug", "rate_limit_read_burst": 100}`)
+	require.NoError(t, live.Reload())
+
+	current := live.Load()
+	require.Equal(t, "debug", current.LogLevel)
+	require.Equal(t, 100, current.RateLimitReadBurst)
+	require.Equal(t, testSource, current.DBSource)
+	require.Equal(t, []Config{current}, notified)
+
+	// Invalid configuration is rejected as a whole.
+	writeFile(t, dir, "config.json", `{"db_source": "`+testSource+`", "log_level": "warn", "rate_limit_read_rate": -1}`)
+	require.Error(t, live.Reload())
+	require.Equal(t, current, live.Load())
+
+	// Nothing is applied when reloadable settings didn't change.
+	writeFile(t, dir, "config.json", `{"db_source": "postgres://another", "log_level": "debug", "rate_limit_read_burst": 100}`)
+	require.NoError(t, live.Reload())
+	require.Equal(t, current, live.Load())
+	require.Len(t, notified, 1)
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"net/url"
+	"time"
+)
+
+const redacted = "REDACTED"
+
+// Returns settings by their keys. Secrets are replaced when redact is set,
+// passwords in URLs are replaced while the rest of URL is kept.
+func (c Config) Map(redact bool) map[string]interface{} {
+	settings := make(map[string]interface{})
+	for _, field := range fields(&c) {
+		value := field.value.Interface()
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+
+		if redact && field.secret {
+			switch secret := value.(type) {
+			case string:
+				value = redactSecret(secret)
+			case []string:
+				secrets := make([]string, len(secret))
+				for i := range secret {
+					secrets[i] = redactSecret(secret[i])
+				}
+				value = secrets
+			}
+		}
+
+		settings[field.key] = value
+	}
+
+	return settings
+}
+
+func redactSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	if u, err := url.Parse(secret); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+			return u.String()
+		}
+	}
+
+	return redacted
+}
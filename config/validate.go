@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Lists all problems found in configuration.
+type ValidationError []string
+
+func (e ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e, "\n  - ")
+}
+
+// Checks that configuration is complete and consistent. Returns ValidationError
+// listing all problems.
+func (c Config) Validate() error {
+	var problems ValidationError
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.DBSource != "", "db_source is required")
+	check(c.DBDriver == "postgres", "db_driver must be postgres, got %q", c.DBDriver)
+	for i, source := range c.DBReplicaSources {
+		check(source != "", "db_replica_sources[%d] must not be empty", i)
+	}
+	check(c.DBMaxOpenConns >= 1, "db_max_open_conns must be at least 1, got %d", c.DBMaxOpenConns)
+	check(c.DBMaxIdleConns >= 0 && c.DBMaxIdleConns <= c.DBMaxOpenConns, "db_max_idle_conns must be between 0 and db_max_open_conns, got %d", c.DBMaxIdleConns)
+	check(c.DBConnMaxLifetime >= 0, "db_conn_max_lifetime must not be negative, got %s", c.DBConnMaxLifetime)
+	check(c.DBConnMaxIdleTime >= 0, "db_conn_max_idle_time must not be negative, got %s", c.DBConnMaxIdleTime)
+	check(c.DBReplicaCheckInterval > 0, "db_replica_check_interval must be positive, got %s", c.DBReplicaCheckInterval)
+	check(validAddress(c.ServerAddress), "server_address must be host:port, got %q", c.ServerAddress)
+	check(validAddress(c.GRPCServerAddress), "grpc_server_address must be host:port, got %q", c.GRPCServerAddress)
+	check(oneOf(c.GinMode, "debug", "release", "test"), "gin_mode must be debug, release or test, got %q", c.GinMode)
+	for i, proxy := range c.TrustedProxies {
+		check(validProxy(proxy), "trusted_proxies[%d] must be an IP address or CIDR, got %q", i, proxy)
+	}
+
+	var level zapcore.Level
+	check(level.UnmarshalText([]byte(c.LogLevel)) == nil, "log_level must be debug, info, warn or error, got %q", c.LogLevel)
+
+	check(c.IdempotencyTTL > 0, "idempotency_ttl must be positive, got %s", c.IdempotencyTTL)
+	check(c.IdempotencyLease > 0, "idempotency_lease must be positive, got %s", c.IdempotencyLease)
+
+	check(oneOf(c.CacheBackend, "lru", "redis", "none"), "cache_backend must be lru, redis or none, got %q", c.CacheBackend)
+	check(c.CacheSize > 0, "cache_size must be positive, got %d", c.CacheSize)
+	check(c.CacheTTL > 0, "cache_ttl must be positive, got %s", c.CacheTTL)
+
+	check(oneOf(c.RateLimitStore, "memory", "redis", "none"), "rate_limit_store must be memory, redis or none, got %q", c.RateLimitStore)
+	check(c.RateLimitReadRate > 0, "rate_limit_read_rate must be positive, got %g", c.RateLimitReadRate)
+	check(c.RateLimitReadBurst >= 1, "rate_limit_read_burst must be at least 1, got %d", c.RateLimitReadBurst)
+	check(c.RateLimitWriteRate > 0, "rate_limit_write_rate must be positive, got %g", c.RateLimitWriteRate)
+	check(c.RateLimitWriteBurst >= 1, "rate_limit_write_burst must be at least 1, got %d", c.RateLimitWriteBurst)
+
+	usesRedis := c.CacheBackend == "redis" || c.RateLimitStore == "redis" || c.AlertPublisher == "redis"
+	check(!usesRedis || c.RedisAddress != "", "redis_address is required when cache_backend, rate_limit_store or alert_publisher is redis")
+	check(c.RedisDB >= 0, "redis_db must not be negative, got %d", c.RedisDB)
+
+	check(oneOf(c.TracingExporter, "otlp", "stdout", "none"), "tracing_exporter must be otlp, stdout or none, got %q", c.TracingExporter)
+	check(c.TracingSampleRatio > 0 && c.TracingSampleRatio <= 1, "tracing_sample_ratio must be in (0, 1], got %g", c.TracingSampleRatio)
+
+	for _, action := range []struct{ key, value string }{
+		{"moderation_profanity_action", c.ModerationProfanityAction},
+		{"moderation_link_action", c.ModerationLinkAction},
+		{"moderation_phone_action", c.ModerationPhoneAction},
+		{"moderation_repeated_chars_action", c.ModerationRepeatedCharsAction},
+		{"moderation_blocklist_action", c.ModerationBlocklistAction},
+	} {
+		check(oneOf(action.value, "reject", "moderate", "mask", "none"), "%s must be reject, moderate, mask or none, got %q", action.key, action.value)
+	}
+	check(c.ModerationMaxRepeatedChars >= 2, "moderation_max_repeated_chars must be at least 2, got %d", c.ModerationMaxRepeatedChars)
+
+	check(oneOf(c.DuplicateAction, "reject", "moderate", "none"), "duplicate_action must be reject, moderate or none, got %q", c.DuplicateAction)
+	check(c.DuplicateThreshold > 0 && c.DuplicateThreshold <= 1, "duplicate_threshold must be in (0, 1], got %g", c.DuplicateThreshold)
+	check(c.DuplicateMinMatches >= 1, "duplicate_min_matches must be at least 1, got %d", c.DuplicateMinMatches)
+	check(c.DuplicateWindow > 0, "duplicate_window must be positive, got %s", c.DuplicateWindow)
+	check(c.DuplicateMinLength >= 5, "duplicate_min_length must be at least 5, got %d", c.DuplicateMinLength)
+
+	check(c.AnomalyInterval > 0, "anomaly_interval must be positive, got %s", c.AnomalyInterval)
+	check(c.AnomalyWindow > 0, "anomaly_window must be positive, got %s", c.AnomalyWindow)
+	check(c.AnomalyBaseline > c.AnomalyWindow, "anomaly_baseline must be longer than anomaly_window, got %s", c.AnomalyBaseline)
+	check(c.AnomalyMinRatings >= 1, "anomaly_min_ratings must be at least 1, got %d", c.AnomalyMinRatings)
+	check(c.AnomalyVelocityFactor >= 1, "anomaly_velocity_factor must be at least 1, got %g", c.AnomalyVelocityFactor)
+	check(c.AnomalyMinDistance >= 0 && c.AnomalyMinDistance <= 1, "anomaly_min_distance must be in [0, 1], got %g", c.AnomalyMinDistance)
+
+	check(oneOf(c.AlertPublisher, "log", "webhook", "redis", "none"), "alert_publisher must be log, webhook, redis or none, got %q", c.AlertPublisher)
+	check(c.AlertPublisher != "webhook" || c.AlertWebhookURL != "", "alert_webhook_url is required when alert_publisher is webhook")
+	check(c.AlertPublisher != "redis" || c.AlertChannel != "", "alert_channel is required when alert_publisher is redis")
+
+	if len(problems) > 0 {
+		return problems
+	}
+
+	return nil
+}
+
+func validAddress(address string) bool {
+	_, port, err := net.SplitHostPort(address)
+	return err == nil && port != ""
+}
+
+func validProxy(proxy string) bool {
+	if _, _, err := net.ParseCIDR(proxy); err == nil {
+		return true
+	}
+	return net.ParseIP(proxy) != nil
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+
+	return false
+}
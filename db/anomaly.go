@@ -0,0 +1,215 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Numbers of ratings of a station by stars, in the detection window and in the
+// baseline period before it.
+type StationWindowStats struct {
+	StationID      int64         `db:"station_id"`
+	WindowStart    time.Time     `db:"window_start"`
+	WindowEnd      time.Time     `db:"window_end"`
+	WindowCounts   pq.Int64Array `db:"window_counts"`
+	BaselineCounts pq.Int64Array `db:"baseline_counts"`
+}
+
+type WindowStatsParam struct {
+	// Window ends at the current time of the database, like default creation
+	// time of ratings, and the baseline ends at the window start.
+	Window   time.Duration
+	Baseline time.Duration
+	// Only stations with at least this many ratings in the window are returned.
+	MinRatings int
+}
+
+// Window of unusual ratings of a station, e.g. a flood of 1-star ratings.
+type Anomaly struct {
+	ID              int64      `json:"anomaly_id" db:"anomaly_id"`
+	StationID       int64      `json:"station_id" db:"station_id"`
+	WindowStart     time.Time  `json:"window_start" db:"window_start"`
+	WindowEnd       time.Time  `json:"window_end" db:"window_end"`
+	RatingCount     int64      `json:"rating_count" db:"rating_count"`
+	ExpectedCount   float64    `json:"expected_count" db:"expected_count"`
+	AverageRating   float64    `json:"average_rating" db:"average_rating"`
+	BaselineAverage float64    `json:"baseline_average" db:"baseline_average"`
+	Distance        float64    `json:"distance" db:"distance"`
+	Frozen          bool       `json:"frozen" db:"frozen"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	ResolvedAt      *time.Time `json:"resolved_at" db:"resolved_at"`
+}
+
+type FlagAnomalyParam struct {
+	StationID       int64
+	WindowStart     time.Time
+	WindowEnd       time.Time
+	RatingCount     int64
+	ExpectedCount   float64
+	AverageRating   float64
+	BaselineAverage float64
+	Distance        float64
+	Frozen          bool
+}
+
+type ListAnomalyParam struct {
+	Offset int32
+	Limit  int32
+	// Only anomalies that were not resolved yet.
+	Open bool
+}
+
+// Returns numbers of ratings by stars of stations that got at least MinRatings
+// ratings in the window.
+func (store *Store) GetWindowStats(ctx context.Context, arg WindowStatsParam) (stats []StationWindowStats, err error) {
+	ctx, end := observe(ctx, "GetWindowStats")
+	defer end()
+
+	const query = `
+	WITH "bounds" AS (
+		SELECT now()::TIMESTAMP AS "window_end",
+			now()::TIMESTAMP - $1::DOUBLE PRECISION * INTERVAL '1 second' AS "window_start",
+			now()::TIMESTAMP - ($1::DOUBLE PRECISION + $2::DOUBLE PRECISION) * INTERVAL '1 second' AS "baseline_start"
+	)
+	SELECT "station_id", "window_start", "window_end",
+		ARRAY[
+			COUNT(*) FILTER (WHERE "created_at" >= "window_start" AND "rating" = 1),
+			COUNT(*) FILTER (WHERE "created_at" >= "window_start" AND "rating" = 2),
+			COUNT(*) FILTER (WHERE "created_at" >= "window_start" AND "rating" = 3),
+			COUNT(*) FILTER (WHERE "created_at" >= "window_start" AND "rating" = 4),
+			COUNT(*) FILTER (WHERE "created_at" >= "window_start" AND "rating" = 5)
+		] AS "window_counts",
+		ARRAY[
+			COUNT(*) FILTER (WHERE "created_at" < "window_start" AND "rating" = 1),
+			COUNT(*) FILTER (WHERE "created_at" < "window_start" AND "rating" = 2),
+			COUNT(*) FILTER (WHERE "created_at" < "window_start" AND "rating" = 3),
+			COUNT(*) FILTER (WHERE "created_at" < "window_start" AND "rating" = 4),
+			COUNT(*) FILTER (WHERE "created_at" < "window_start" AND "rating" = 5)
+		] AS "baseline_counts"
+	FROM "ratings", "bounds"
+	WHERE "created_at" >= "baseline_start" AND "created_at" < "window_end"
+		AND "station_id" IN (
+			SELECT "station_id" FROM "ratings", "bounds"
+			WHERE "created_at" >= "window_start" AND "created_at" < "window_end"
+			GROUP BY "station_id"
+			HAVING COUNT(*) >= $3
+		)
+	GROUP BY "station_id", "window_start", "window_end"
+	ORDER BY "station_id"
+	`
+	stats = []StationWindowStats{}
+	err = store.db.SelectContext(ctx, &stats, query, arg.Window.Seconds(), arg.Baseline.Seconds(), arg.MinRatings)
+
+	return
+}
+
+// Records anomaly of a station. Open anomaly of the station is extended instead,
+// created reports whether a new anomaly was recorded.
+func (store *Store) FlagAnomaly(ctx context.Context, arg FlagAnomalyParam) (anomaly Anomaly, created bool, err error) {
+	ctx, end := observe(ctx, "FlagAnomaly")
+	defer end()
+
+	const query = `
+	INSERT INTO "anomalies"("station_id", "window_start", "window_end", "rating_count",
+		"expected_count", "average_rating", "baseline_average", "distance", "frozen")
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	ON CONFLICT ("station_id") WHERE "resolved_at" IS NULL DO UPDATE
+	SET "window_end" = EXCLUDED."window_end",
+		"rating_count" = GREATEST("anomalies"."rating_count", EXCLUDED."rating_count"),
+		"expected_count" = EXCLUDED."expected_count",
+		"average_rating" = EXCLUDED."average_rating",
+		"distance" = GREATEST("anomalies"."distance", EXCLUDED."distance"),
+		"frozen" = "anomalies"."frozen" OR EXCLUDED."frozen"
+	RETURNING *, ("xmax" = 0) AS "created"
+	`
+	var result struct {
+		Anomaly
+		Created bool `db:"created"`
+	}
+	err = store.db.GetContext(ctx, &result, query, arg.StationID, arg.WindowStart, arg.WindowEnd, arg.RatingCount,
+		arg.ExpectedCount, arg.AverageRating, arg.BaselineAverage, arg.Distance, arg.Frozen)
+	if err != nil {
+		return
+	}
+
+	// Summary of a frozen station changes.
+	if result.Created && result.Frozen {
+		store.notifyWrite(ctx, result.StationID)
+	}
+
+	return result.Anomaly, result.Created, nil
+}
+
+/// ListAnomalies godoc
+// @Summary      List anomalies
+// @Description  list windows of unusual ratings of stations, newest first
+// @ID           list-anomalies
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        open   query      bool  false  "Only anomalies that were not resolved yet"
+// @Param        offset   query      int  false  "Offset"
+// @Param        limit   query      int  true  "Limit"
+// @Success      200  {object}  []Anomaly
+// @Failure      400  {object}  HTTPError400
+// @Failure      401  {object}  HTTPError401
+// @Failure      403  {object}  HTTPError403
+// @Failure      500  {object}  HTTPError500
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/anomalies [get]
+func (store *Store) ListAnomalies(ctx context.Context, arg ListAnomalyParam) (anomalies []Anomaly, err error) {
+	ctx, end := observe(ctx, "ListAnomalies")
+	defer end()
+
+	const query = `
+	SELECT * FROM "anomalies"
+	WHERE NOT $1 OR "resolved_at" IS NULL
+	ORDER BY "window_start" DESC, "anomaly_id" DESC
+	OFFSET $2 LIMIT $3
+	`
+	anomalies = []Anomaly{}
+	err = store.db.SelectContext(ctx, &anomalies, query, arg.Open, arg.Offset, arg.Limit)
+
+	return
+}
+
+/// ResolveAnomaly godoc
+// @Summary      Resolve an anomaly
+// @Description  close anomaly, so that summary of a frozen station includes all ratings again
+// @ID           resolve-anomaly
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Anomaly ID"
+// @Success      200  {object}  Anomaly
+// @Failure      400  {object}  HTTPError400
+// @Failure      401  {object}  HTTPError401
+// @Failure      403  {object}  HTTPError403
+// @Failure      404  {object}  HTTPError404
+// @Failure      500  {object}  HTTPError500
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/anomalies/{id}/resolve [post]
+func (store *Store) ResolveAnomaly(ctx context.Context, id int64) (anomaly Anomaly, err error) {
+	ctx, end := observe(ctx, "ResolveAnomaly")
+	defer end()
+
+	const query = `
+	UPDATE "anomalies"
+	SET "resolved_at" = COALESCE("resolved_at", now())
+	WHERE "anomaly_id" = $1
+	RETURNING *
+	`
+	if err = store.db.GetContext(ctx, &anomaly, query, id); err != nil {
+		return
+	}
+
+	if anomaly.Frozen {
+		store.notifyWrite(ctx, anomaly.StationID)
+	}
+
+	return
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"rating-service/util"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagAnomaly(t *testing.T) {
+	ctx := context.Background()
+	stationID := util.RandomInt(1261, 654561)
+
+	// Flood station with 1-star ratings.
+	for i := 0; i < 12; i++ {
+		_, err := testStore.Create(ctx, CreateRatingParam{
+			Station_id: stationID,
+			User_id:    util.RandomInt(1261, 654561),
+			Rating:     1,
+			Comment:    util.RandomComment(1),
+		})
+		require.NoError(t, err)
+	}
+
+	stats, err := testStore.GetWindowStats(ctx, WindowStatsParam{
+		Window:     time.Hour,
+		Baseline:   24 * time.Hour,
+		MinRatings: 10,
+	})
+	require.NoError(t, err)
+
+	var station *StationWindowStats
+	for i := range stats {
+		if stats[i].StationID == stationID {
+			station = &stats[i]
+		}
+	}
+	require.NotNil(t, station)
+	require.Equal(t, []int64{12, 0, 0, 0, 0}, []int64(station.WindowCounts))
+	require.Equal(t, []int64{0, 0, 0, 0, 0}, []int64(station.BaselineCounts))
+	require.Equal(t, time.Hour, station.WindowEnd.Sub(station.WindowStart))
+
+	// Bounds come from the database clock, like creation times of ratings.
+	rating, err := testStore.Create(ctx, CreateRatingParam{Station_id: stationID, User_id: 1261, Rating: 1})
+	require.NoError(t, err)
+	require.False(t, rating.CreatedAt.Before(station.WindowEnd))
+	require.NoError(t, testStore.Delete(ctx, rating.ID))
+
+	now := station.WindowEnd
+	arg := FlagAnomalyParam{
+		StationID:     stationID,
+		WindowStart:   station.WindowStart,
+		WindowEnd:     now,
+		RatingCount:   12,
+		AverageRating: 1,
+		Distance:      0.8,
+		Frozen:        true,
+	}
+	anomaly, created, err := testStore.FlagAnomaly(ctx, arg)
+	require.NoError(t, err)
+	require.True(t, created)
+	require.True(t, anomaly.Frozen)
+
+	// Open anomaly is extended.
+	arg.WindowStart = now
+	arg.WindowEnd = now.Add(time.Minute)
+	extended, created, err := testStore.FlagAnomaly(ctx, arg)
+	require.NoError(t, err)
+	require.False(t, created)
+	require.Equal(t, anomaly.ID, extended.ID)
+	require.Equal(t, anomaly.WindowStart, extended.WindowStart)
+	require.True(t, extended.WindowEnd.After(anomaly.WindowEnd))
+
+	// Summary of frozen station leaves out ratings since the window start.
+	summary, err := testStore.GetStationSummary(ctx, stationID)
+	require.NoError(t, err)
+	require.True(t, summary.Frozen)
+	require.Zero(t, summary.RatingCount)
+
+	anomalies, err := testStore.ListAnomalies(ctx, ListAnomalyParam{Open: true, Limit: 1000})
+	require.NoError(t, err)
+	require.Contains(t, anomalyIDs(anomalies), anomaly.ID)
+
+	resolved, err := testStore.ResolveAnomaly(ctx, anomaly.ID)
+	require.NoError(t, err)
+	require.NotNil(t, resolved.ResolvedAt)
+
+	summary, err = testStore.GetStationSummary(ctx, stationID)
+	require.NoError(t, err)
+	require.False(t, summary.Frozen)
+	require.Equal(t, int64(12), summary.RatingCount)
+
+	anomalies, err = testStore.ListAnomalies(ctx, ListAnomalyParam{Open: true, Limit: 1000})
+	require.NoError(t, err)
+	require.NotContains(t, anomalyIDs(anomalies), anomaly.ID)
+}
+
+func anomalyIDs(anomalies []Anomaly) []int64 {
+	result := make([]int64, len(anomalies))
+	for i, anomaly := range anomalies {
+		result[i] = anomaly.ID
+	}
+
+	return result
+}
@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type APIKey struct {
+	ID        int64          `json:"api_key_id" db:"api_key_id"`
+	Name      string         `json:"name" db:"name"`
+	Prefix    string         `json:"prefix" db:"prefix"`
+	KeyHash   string         `json:"-" db:"key_hash"`
+	Scopes    pq.StringArray `json:"scopes" db:"scopes" swaggertype:"array,string"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+	RotatedAt *time.Time     `json:"rotated_at" db:"rotated_at"`
+	RevokedAt *time.Time     `json:"revoked_at" db:"revoked_at"`
+}
+
+type CreateAPIKeyParam struct {
+	Name    string   `json:"name"`
+	Scopes  []string `json:"scopes"`
+	Prefix  string   `json:"-"`
+	KeyHash string   `json:"-"`
+}
+
+// Newly created or rotated API key. Key is only returned once.
+type IssuedAPIKey struct {
+	APIKey APIKey `json:"api_key"`
+	Key    string `json:"key"`
+}
+
+/// CreateAPIKey godoc
+// @Summary      Create a new API key
+// @Description  create API key for service-to-service callers, the key is only returned once
+// @ID           create-api-key
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        message  body  CreateAPIKeyParam  true  "API key parametres"
+// @Success      201  {object}  IssuedAPIKey
+// @Failure      400  {object}  HTTPError400
+// @Failure      401  {object}  HTTPError401
+// @Failure      403  {object}  HTTPError403
+// @Failure      500  {object}  HTTPError500
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/api-keys [post]
+func (store *Store) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParam) (key APIKey, err error) {
+	ctx, end := observe(ctx, "CreateAPIKey")
+	defer end()
+
+	const query = `
+	INSERT INTO "api_keys"("name", "prefix", "key_hash", "scopes")
+	VALUES ($1, $2, $3, $4)
+	RETURNING *
+	`
+	err = store.db.GetContext(ctx, &key, query, arg.Name, arg.Prefix, arg.KeyHash, pq.Array(arg.Scopes))
+
+	return
+}
+
+// Returns API key with given prefix, including revoked keys.
+func (store *Store) GetAPIKeyByPrefix(ctx context.Context, prefix string) (key APIKey, err error) {
+	ctx, end := observe(ctx, "GetAPIKeyByPrefix")
+	defer end()
+
+	const query = `SELECT * FROM "api_keys" WHERE "prefix" = $1`
+	err = store.db.GetContext(ctx, &key, query, prefix)
+
+	return
+}
+
+/// ListAPIKeys godoc
+// @Summary      Get all API keys
+// @Description  get all API keys without their secrets
+// @ID           list-api-keys
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  []APIKey
+// @Failure      401  {object}  HTTPError401
+// @Failure      403  {object}  HTTPError403
+// @Failure      500  {object}  HTTPError500
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/api-keys [get]
+func (store *Store) ListAPIKeys(ctx context.Context) (keys []APIKey, err error) {
+	ctx, end := observe(ctx, "ListAPIKeys")
+	defer end()
+
+	const query = `SELECT * FROM "api_keys" ORDER BY "api_key_id"`
+	keys = []APIKey{}
+	err = store.db.SelectContext(ctx, &keys, query)
+
+	return
+}
+
+/// RotateAPIKey godoc
+// @Summary      Rotate an API key
+// @Description  replace secret of an API key, the old secret stops working immediately
+// @ID           rotate-api-key
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      int  true  "API key ID"
+// @Success      200  {object}  IssuedAPIKey
+// @Failure      401  {object}  HTTPError401
+// @Failure      403  {object}  HTTPError403
+// @Failure      404  {object}  HTTPError404
+// @Failure      500  {object}  HTTPError500
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/api-keys/{id}/rotate [post]
+func (store *Store) RotateAPIKey(ctx context.Context, id int64, prefix, keyHash string) (key APIKey, err error) {
+	ctx, end := observe(ctx, "RotateAPIKey")
+	defer end()
+
+	const query = `
+	UPDATE "api_keys"
+	SET "prefix" = $2,
+		"key_hash" = $3,
+		"rotated_at" = now()
+	WHERE "api_key_id" = $1 AND "revoked_at" IS NULL
+	RETURNING *
+	`
+	err = store.db.GetContext(ctx, &key, query, id, prefix, keyHash)
+
+	return
+}
+
+/// RevokeAPIKey godoc
+// @Summary      Revoke an API key
+// @Description  revoke API key
+// @ID           revoke-api-key
+// @Tags         admin
+// @Param        id   path      int  true  "API key ID"
+// @Success      204
+// @Failure      401  {object}  HTTPError401
+// @Failure      403  {object}  HTTPError403
+// @Failure      404  {object}  HTTPError404
+// @Failure      500  {object}  HTTPError500
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /admin/api-keys/{id} [delete]
+func (store *Store) RevokeAPIKey(ctx context.Context, id int64) (key APIKey, err error) {
+	ctx, end := observe(ctx, "RevokeAPIKey")
+	defer end()
+
+	const query = `
+	UPDATE "api_keys"
+	SET "revoked_at" = COALESCE("revoked_at", now())
+	WHERE "api_key_id" = $1
+	RETURNING *
+	`
+	err = store.db.GetContext(ctx, &key, query, id)
+
+	return
+}
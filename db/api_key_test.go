@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"rating-service/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAPIKey(t *testing.T) APIKey {
+	arg := CreateAPIKeyParam{
+		Name:    util.RandomString(10),
+		Scopes:  []string{"ratings:read", "ratings:write"},
+		Prefix:  util.RandomString(12),
+		KeyHash: util.RandomString(64),
+	}
+
+	key, err := testStore.CreateAPIKey(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.NotZero(t, key.ID)
+	require.Equal(t, arg.Name, key.Name)
+	require.Equal(t, arg.Prefix, key.Prefix)
+	require.Equal(t, arg.KeyHash, key.KeyHash)
+	require.Equal(t, arg.Scopes, []string(key.Scopes))
+	require.NotZero(t, key.CreatedAt)
+	require.Nil(t, key.RevokedAt)
+
+	return key
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	createRandomAPIKey(t)
+}
+
+func TestGetAPIKeyByPrefix(t *testing.T) {
+	key1 := createRandomAPIKey(t)
+
+	key2, err := testStore.GetAPIKeyByPrefix(context.Background(), key1.Prefix)
+	require.NoError(t, err)
+	require.Equal(t, key1, key2)
+}
+
+func TestRotateAPIKey(t *testing.T) {
+	key1 := createRandomAPIKey(t)
+
+	prefix := util.RandomString(12)
+	hash := util.RandomString(64)
+	key2, err := testStore.RotateAPIKey(context.Background(), key1.ID, prefix, hash)
+	require.NoError(t, err)
+
+	require.Equal(t, key1.ID, key2.ID)
+	require.Equal(t, prefix, key2.Prefix)
+	require.Equal(t, hash, key2.KeyHash)
+	require.NotNil(t, key2.RotatedAt)
+
+	_, err = testStore.GetAPIKeyByPrefix(context.Background(), key1.Prefix)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	key1 := createRandomAPIKey(t)
+
+	key2, err := testStore.RevokeAPIKey(context.Background(), key1.ID)
+	require.NoError(t, err)
+	require.NotNil(t, key2.RevokedAt)
+
+	// Revoked keys can't be rotated.
+	_, err = testStore.RotateAPIKey(context.Background(), key1.ID, util.RandomString(12), util.RandomString(64))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	keys, err := testStore.ListAPIKeys(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, keys)
+}
@@ -1,16 +1,40 @@
 package db
 
 import (
-	"log"
+	"context"
+	"database/sql"
+	"rating-service/metrics"
+	"rating-service/moderation"
+	"rating-service/similarity"
+	"sync"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
+var tracer = otel.Tracer("rating-service/db")
+
 type Store struct {
-	db *sqlx.DB
+	db         *sqlx.DB
+	replicas   []*replica
+	next       uint32
+	stop       chan struct{}
+	closeOnce  sync.Once
+	filter     *moderation.Pipeline
+	duplicates *similarity.Options
+	hooks      []WriteHook
 }
 
+// Called after ratings of given stations were created, changed or deleted.
+type WriteHook func(ctx context.Context, stationIDs ...int64)
+
 func Connect(source, driver string) (*Store, error) {
 	// Connect to database.
 	db, err := sqlx.Connect(source, driver)
@@ -19,13 +43,61 @@ func Connect(source, driver string) (*Store, error) {
 	}
 
 	store := &Store{
-		db: db,
+		db:   db,
+		stop: make(chan struct{}),
 	}
 
-	log.Println("Connected to database!")
+	zap.L().Info("Connected to database")
 	return store, err
 }
 
-func (store *Store) PingDB() error {
-	return store.db.Ping()
+func (store *Store) Ping(ctx context.Context) error {
+	return store.db.PingContext(ctx)
+}
+
+// Returns statistics of connection pool of primary database.
+func (store *Store) Stats() sql.DBStats {
+	return store.db.Stats()
+}
+
+// Registers connection pool statistics of primary database and replicas on given registry.
+func (store *Store) RegisterMetrics(registerer prometheus.Registerer) error {
+	if err := registerer.Register(collectors.NewDBStatsCollector(store.db.DB, "primary")); err != nil {
+		return err
+	}
+
+	for _, r := range store.replicas {
+		if err := registerer.Register(collectors.NewDBStatsCollector(r.db.DB, r.name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Starts a span and measures duration of a store method. Queries must use returned
+// context, and returned function must be called when method returns.
+func observe(ctx context.Context, method string) (context.Context, func()) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, "Store."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "postgresql")),
+	)
+
+	return ctx, func() {
+		span.End()
+		metrics.QueryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Registers a hook that is called after every committed write. Hooks must be
+// registered before the store is used.
+func (store *Store) OnWrite(hook WriteHook) {
+	store.hooks = append(store.hooks, hook)
+}
+
+func (store *Store) notifyWrite(ctx context.Context, stationIDs ...int64) {
+	for _, hook := range store.hooks {
+		hook(ctx, stationIDs...)
+	}
 }
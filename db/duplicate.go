@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"rating-service/metrics"
+	"rating-service/moderation"
+	"rating-service/similarity"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Limits number of candidates a new comment is compared with.
+const maxDuplicateCandidates = 1000
+
+// Signature of a stored comment.
+type commentSignature struct {
+	RatingID  int64         `db:"rating_id"`
+	UserID    int64         `db:"user_id"`
+	Signature pq.Int64Array `db:"signature"`
+}
+
+type BackfillSignaturesParam struct {
+	// Sign all comments again instead of only comments without signature, e.g.
+	// after duplicate_min_length changed.
+	All       bool
+	BatchSize int
+}
+
+type BackfillSignaturesReport struct {
+	Scanned int64 `json:"scanned"`
+	Changed int64 `json:"changed"`
+}
+
+// Enables detection of near duplicate comments of created ratings. It must be set
+// before the store is used.
+func (store *Store) SetDuplicateDetection(options similarity.Options) {
+	store.duplicates = &options
+}
+
+// Compares comment with stored comments of the same user and recent comments of
+// all users, and with unstored comments that are about to be stored together with
+// it. Changed comment of rating ratingID is not compared with its own stored
+// signature, ratingID is 0 for new ratings. Adds reason to moderated comment when
+// it is a duplicate and returns RejectedError when it must not be stored. Returns
+// signature of the comment, nil when detection is disabled or comment is too short.
+func (store *Store) checkDuplicates(ctx context.Context, q sqlx.QueryerContext, ratingID, userID int64, moderated *moderatedComment, unstored []commentSignature) (similarity.Signature, error) {
+	options := store.duplicates
+	if options == nil || options.Action == moderation.ActionNone {
+		return nil, nil
+	}
+
+	signature, ok := similarity.NewSignature(moderated.Comment, options.MinLength)
+	if !ok {
+		return nil, nil
+	}
+
+	// Candidates share a band with the comment. Primary is used, so that comments
+	// posted in quick succession are compared.
+	const query = `
+	SELECT "rating_id", "user_id", "signature" FROM "comment_signatures"
+	WHERE "bands" && $1 AND ("user_id" = $2 OR "created_at" >= now() - make_interval(secs => $3)) AND "rating_id" <> $5
+	ORDER BY "created_at" DESC
+	LIMIT $4
+	`
+	candidates := []commentSignature{}
+	err := sqlx.SelectContext(ctx, q, &candidates, query, pq.Int64Array(signature.Bands()), userID, options.Window.Seconds(), maxDuplicateCandidates, ratingID)
+	if err != nil {
+		return nil, err
+	}
+
+	var reason string
+	matches := 0
+	for _, candidate := range append(candidates, unstored...) {
+		score := signature.Similarity(similarity.Signature(candidate.Signature))
+		if score < options.Threshold {
+			continue
+		}
+		if candidate.UserID == userID {
+			reason = fmt.Sprintf("similar to rating %d of the same user (%.2f)", candidate.RatingID, score)
+			break
+		}
+		matches++
+	}
+	if reason == "" && matches >= options.MinMatches {
+		reason = fmt.Sprintf("similar to %d recent comments", matches)
+	}
+	if reason == "" {
+		return signature, nil
+	}
+
+	metrics.ModerationActions.WithLabelValues(string(options.Action)).Inc()
+	moderated.Reasons = append(moderated.Reasons, moderation.Reason{Filter: "duplicate", Action: options.Action, Reason: reason})
+	switch options.Action {
+	case moderation.ActionReject:
+		return signature, &RejectedError{Reasons: moderated.Reasons}
+	case moderation.ActionModerate:
+		moderated.Status = StatusPending
+	}
+
+	return signature, nil
+}
+
+// Returns signature and its bands as query parameters, null when there is no
+// signature.
+func signatureParams(signature similarity.Signature) (values, bands pq.Int64Array) {
+	if signature != nil {
+		values, bands = pq.Int64Array(signature), pq.Int64Array(signature.Bands())
+	}
+	return
+}
+
+// Stores signatures of comments of existing ratings in batches, so that new
+// comments are compared with them. Comments are not checked, and signatures keep
+// time of their rating, so that old comments don't count as recent ones.
+func (store *Store) BackfillSignatures(ctx context.Context, arg BackfillSignaturesParam) (report BackfillSignaturesReport, err error) {
+	ctx, end := observe(ctx, "BackfillSignatures")
+	defer end()
+
+	options := store.duplicates
+	if options == nil || options.Action == moderation.ActionNone {
+		return report, fmt.Errorf("duplicate detection is disabled")
+	}
+
+	const selectQuery = `
+	SELECT "ratings"."rating_id", "ratings"."user_id", COALESCE("ratings"."comment", '') AS "comment" FROM "ratings"
+	LEFT JOIN "comment_signatures" ON "comment_signatures"."rating_id" = "ratings"."rating_id"
+	WHERE "ratings"."rating_id" > $1 AND ($2 OR "comment_signatures"."rating_id" IS NULL)
+	ORDER BY "ratings"."rating_id"
+	LIMIT $3
+	`
+	// Signatures are passed as array literals, as arrays of arrays can't be
+	// unnested into rows. Comments that are too short lose their signature.
+	const updateQuery = `
+	WITH "signatures" AS (
+		SELECT * FROM unnest($1::BIGINT[], $2::BIGINT[], $3::TEXT[], $4::TEXT[], $5::BOOLEAN[])
+			AS "signatures"("rating_id", "user_id", "signature", "bands", "signed")
+	), "stored" AS (
+		INSERT INTO "comment_signatures"("rating_id", "user_id", "signature", "bands", "created_at")
+		SELECT "signatures"."rating_id", "signatures"."user_id", "signature"::BIGINT[], "bands"::BIGINT[], "ratings"."created_at"
+		FROM "signatures" JOIN "ratings" ON "ratings"."rating_id" = "signatures"."rating_id"
+		WHERE "signed"
+		ON CONFLICT ("rating_id") DO UPDATE
+		SET "user_id" = EXCLUDED."user_id", "signature" = EXCLUDED."signature", "bands" = EXCLUDED."bands"
+		WHERE "comment_signatures"."signature" IS DISTINCT FROM EXCLUDED."signature"
+			OR "comment_signatures"."user_id" <> EXCLUDED."user_id"
+		RETURNING "rating_id"
+	), "removed" AS (
+		DELETE FROM "comment_signatures"
+		WHERE "rating_id" IN (SELECT "rating_id" FROM "signatures" WHERE NOT "signed")
+		RETURNING "rating_id"
+	)
+	SELECT (SELECT count(*) FROM "stored") + (SELECT count(*) FROM "removed")
+	`
+
+	var after int64
+	for {
+		var rows []struct {
+			ID      int64  `db:"rating_id"`
+			UserID  int64  `db:"user_id"`
+			Comment string `db:"comment"`
+		}
+		if err = store.db.SelectContext(ctx, &rows, selectQuery, after, arg.All, arg.BatchSize); err != nil {
+			return
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		ids := make(pq.Int64Array, len(rows))
+		userIDs := make(pq.Int64Array, len(rows))
+		signatures := make(pq.StringArray, len(rows))
+		bands := make(pq.StringArray, len(rows))
+		signed := make(pq.BoolArray, len(rows))
+		for i, row := range rows {
+			ids[i], userIDs[i] = row.ID, row.UserID
+
+			signature, ok := similarity.NewSignature(row.Comment, options.MinLength)
+			if ok {
+				signatures[i], bands[i] = arrayLiteral(signature), arrayLiteral(signature.Bands())
+			}
+			signed[i] = ok
+		}
+		after = rows[len(rows)-1].ID
+		report.Scanned += int64(len(rows))
+
+		var changed int64
+		if err = store.db.GetContext(ctx, &changed, updateQuery, ids, userIDs, signatures, bands, signed); err != nil {
+			return
+		}
+		report.Changed += changed
+	}
+
+	return
+}
+
+// Formats values as a Postgres array literal, e.g. {1,2,3}.
+func arrayLiteral(values []int64) string {
+	literal := make([]string, len(values))
+	for i, value := range values {
+		literal[i] = strconv.FormatInt(value, 10)
+	}
+	return "{" + strings.Join(literal, ",") + "}"
+}
@@ -0,0 +1,193 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"rating-service/moderation"
+	"rating-service/similarity"
+	"rating-service/util"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateDuplicateRating(t *testing.T) {
+	store := moderatedStore(t)
+	defer store.Close()
+	store.SetDuplicateDetection(similarity.Options{
+		Action:     moderation.ActionModerate,
+		Threshold:  0.8,
+		MinMatches: 5,
+		Window:     time.Hour,
+		MinLength:  20,
+	})
+
+	ctx := context.Background()
+	arg := CreateRatingParam{
+		Station_id: util.RandomInt(1261, 654561),
+		User_id:    util.RandomInt(1261, 654561),
+		Rating:     1,
+		Comment:    randomText(),
+	}
+
+	first, err := store.Create(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, StatusPublished, first.Status)
+
+	// Same comment of the same user at another station waits for moderation.
+	arg.Station_id = util.RandomInt(1261, 654561)
+	second, err := store.Create(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, second.Status)
+	require.Len(t, second.ModerationReasons, 1)
+	require.Equal(t, "duplicate", second.ModerationReasons[0].Filter)
+
+	// Different comment is published.
+	arg.Comment = randomText()
+	third, err := store.Create(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, StatusPublished, third.Status)
+
+	// Duplicates are rejected when configured so.
+	store.SetDuplicateDetection(similarity.Options{
+		Action:     moderation.ActionReject,
+		Threshold:  0.8,
+		MinMatches: 5,
+		Window:     time.Hour,
+		MinLength:  20,
+	})
+	_, err = store.Create(ctx, arg)
+	var rejected *RejectedError
+	require.ErrorAs(t, err, &rejected)
+}
+
+func TestImportDuplicateRatings(t *testing.T) {
+	store := moderatedStore(t)
+	defer store.Close()
+	store.SetDuplicateDetection(similarity.Options{
+		Action:     moderation.ActionModerate,
+		Threshold:  0.8,
+		MinMatches: 5,
+		Window:     time.Hour,
+		MinLength:  20,
+	})
+
+	// Rows are compared with earlier rows of the same import.
+	stationID := util.RandomInt(1000000, 2000000)
+	arg := ImportRatingParam{Station_id: stationID, User_id: util.RandomInt(1261, 654561), Rating: 1, Comment: randomText()}
+	source := &sliceSource{records: []ImportRecord{{Row: 2, Rating: arg}, {Row: 3, Rating: arg}}}
+
+	report, err := store.Import(context.Background(), source, false)
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Imported)
+
+	ratings, err := store.GetAllByStation(context.Background(), stationID)
+	require.NoError(t, err)
+	require.Len(t, ratings, 1)
+
+	// Imported comments are compared with later ones.
+	_, err = store.Create(context.Background(), CreateRatingParam{Station_id: stationID, User_id: arg.User_id, Rating: 1, Comment: arg.Comment})
+	require.NoError(t, err)
+	ratings, err = store.GetAllByStation(context.Background(), stationID)
+	require.NoError(t, err)
+	require.Len(t, ratings, 1)
+}
+
+func TestChangeDuplicateRating(t *testing.T) {
+	store := moderatedStore(t)
+	defer store.Close()
+	store.SetDuplicateDetection(similarity.Options{
+		Action:     moderation.ActionModerate,
+		Threshold:  0.8,
+		MinMatches: 5,
+		Window:     time.Hour,
+		MinLength:  20,
+	})
+
+	ctx := context.Background()
+	userID := util.RandomInt(1261, 654561)
+	first, err := store.Create(ctx, CreateRatingParam{Station_id: util.RandomInt(1261, 654561), User_id: userID, Rating: 1, Comment: randomText()})
+	require.NoError(t, err)
+
+	// Rating is not compared with its own comment.
+	first, err = store.Update(ctx, UpdateRatingParam{Station_id: first.Station_id, User_id: userID, Rating: 2, Comment: first.Comment, Version: first.Version}, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusPublished, first.Status)
+
+	second, err := store.Create(ctx, CreateRatingParam{Station_id: util.RandomInt(1261, 654561), User_id: userID, Rating: 1, Comment: randomText()})
+	require.NoError(t, err)
+	require.Equal(t, StatusPublished, second.Status)
+
+	// Comment changed to a copy of another comment waits for moderation.
+	second, err = store.Patch(ctx, PatchRatingParam{Comment: &first.Comment, Version: second.Version}, second.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, second.Status)
+	require.Len(t, second.ModerationReasons, 1)
+	require.Equal(t, "duplicate", second.ModerationReasons[0].Filter)
+
+	// Signature follows the comment.
+	var signature pq.Int64Array
+	err = store.db.GetContext(ctx, &signature, `SELECT "signature" FROM "comment_signatures" WHERE "rating_id" = $1`, second.ID)
+	require.NoError(t, err)
+	expected, ok := similarity.NewSignature(first.Comment, 20)
+	require.True(t, ok)
+	require.Equal(t, pq.Int64Array(expected), signature)
+
+	// Short comment has no signature.
+	short := "Fine."
+	_, err = store.Patch(ctx, PatchRatingParam{Comment: &short, Version: second.Version}, second.ID)
+	require.NoError(t, err)
+	err = store.db.GetContext(ctx, &signature, `SELECT "signature" FROM "comment_signatures" WHERE "rating_id" = $1`, second.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestBackfillSignatures(t *testing.T) {
+	store := moderatedStore(t)
+	defer store.Close()
+
+	// Backfill needs duplicate detection.
+	_, err := store.BackfillSignatures(context.Background(), BackfillSignaturesParam{BatchSize: 10})
+	require.Error(t, err)
+
+	store.SetDuplicateDetection(similarity.Options{
+		Action:     moderation.ActionModerate,
+		Threshold:  0.8,
+		MinMatches: 5,
+		Window:     time.Hour,
+		MinLength:  20,
+	})
+
+	ctx := context.Background()
+	rating, err := store.Create(ctx, CreateRatingParam{Station_id: util.RandomInt(1261, 654561), User_id: util.RandomInt(1261, 654561), Rating: 4, Comment: randomText()})
+	require.NoError(t, err)
+
+	// Ratings stored before duplicate detection was introduced have no signature.
+	_, err = store.db.ExecContext(ctx, `DELETE FROM "comment_signatures" WHERE "rating_id" = $1`, rating.ID)
+	require.NoError(t, err)
+
+	report, err := store.BackfillSignatures(ctx, BackfillSignaturesParam{BatchSize: 10})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, report.Changed, int64(1))
+
+	var backfilled struct {
+		UserID    int64     `db:"user_id"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+	err = store.db.GetContext(ctx, &backfilled, `SELECT "user_id", "created_at" FROM "comment_signatures" WHERE "rating_id" = $1`, rating.ID)
+	require.NoError(t, err)
+	require.Equal(t, rating.User_id, backfilled.UserID)
+	require.WithinDuration(t, rating.CreatedAt, backfilled.CreatedAt, time.Millisecond)
+}
+
+// Returns random words, that are long enough to be compared.
+func randomText() string {
+	words := make([]string, 6)
+	for i := range words {
+		words[i] = util.RandomString(8)
+	}
+
+	return strings.Join(words, " ")
+}
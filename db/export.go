@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Number of rows fetched from export cursor at once.
+const exportBatchSize = 1000
+
+// FETCH doesn't accept bind parameters, so batch size is part of the statement.
+var exportFetchQuery = fmt.Sprintf(`FETCH %d FROM "ratings_export"`, exportBatchSize)
+
+type RatingFilter struct {
+	StationID int64
+	From      time.Time
+	To        time.Time
+}
+
+/// Export godoc
+// @Summary      Export ratings as CSV, NDJSON or Parquet
+// @Description  stream ratings filtered by station and creation date
+// @ID           export-ratings
+// @Tags         ratings
+// @Produce      octet-stream
+// @Param        format   query      string  true  "Format of export (csv, ndjson or parquet)"
+// @Param        station_id   query      int  false  "ID of station"
+// @Param        from   query      string  false  "Include ratings created at or after (RFC 3339)"
+// @Param        to   query      string  false  "Include ratings created before (RFC 3339)"
+// @Success      200  {file}  file
+// @Failure      400  {object}  HTTPError400
+// @Failure      500  {object}  HTTPError500
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /ratings/export [get]
+func (store *Store) Export(ctx context.Context, filter RatingFilter, fn func(Rating) error) error {
+	ctx, end := observe(ctx, "Export")
+	defer end()
+
+	const query = `
+	DECLARE "ratings_export" NO SCROLL CURSOR FOR
+	SELECT ` + ratingColumns + ` FROM "ratings"
+	WHERE ($1 = 0 OR "station_id" = $1)
+		AND ($2::TIMESTAMP IS NULL OR "created_at" >= $2)
+		AND ($3::TIMESTAMP IS NULL OR "created_at" < $3)
+	ORDER BY "rating_id"
+	`
+
+	// Cursors only live inside of a transaction.
+	tx, err := store.db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, query, filter.StationID, nullTime(filter.From), nullTime(filter.To)); err != nil {
+		return err
+	}
+
+	for {
+		rows, err := tx.QueryxContext(ctx, exportFetchQuery)
+		if err != nil {
+			return err
+		}
+
+		fetched := 0
+		for rows.Next() {
+			var rating Rating
+			if err := rows.StructScan(&rating); err != nil {
+				rows.Close()
+				return err
+			}
+
+			if err := fn(rating); err != nil {
+				rows.Close()
+				return err
+			}
+			fetched++
+		}
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		rows.Close()
+
+		if fetched < exportBatchSize {
+			return tx.Commit()
+		}
+	}
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"rating-service/util"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportRatings(t *testing.T) {
+	stationID := util.RandomInt(1000000, 2000000)
+
+	arg := CreateRatingParam{
+		Station_id: stationID,
+		User_id:    util.RandomInt(1261, 654561),
+		Rating:     util.RandomInt(1, 5),
+		Comment:    util.RandomString(5),
+	}
+
+	var created []Rating
+	for i := 0; i < 3; i++ {
+		rating, err := testStore.Create(context.Background(), arg)
+		require.NoError(t, err)
+		created = append(created, rating)
+	}
+
+	filter := RatingFilter{
+		StationID: stationID,
+		From:      created[0].CreatedAt.Add(-time.Minute),
+	}
+
+	var exported []Rating
+	err := testStore.Export(context.Background(), filter, func(rating Rating) error {
+		exported = append(exported, rating)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, created, exported)
+
+	// Ratings created after upper bound are skipped.
+	filter.To = created[0].CreatedAt.Add(-time.Second)
+	exported = nil
+	err = testStore.Export(context.Background(), filter, func(rating Rating) error {
+		exported = append(exported, rating)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Empty(t, exported)
+}
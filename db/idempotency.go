@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type IdempotencyKey struct {
+	Subject        string    `json:"subject" db:"subject"`
+	Key            string    `json:"idempotency_key" db:"idempotency_key"`
+	RequestHash    string    `json:"request_hash" db:"request_hash"`
+	ResponseStatus int       `json:"response_status" db:"response_status"`
+	ResponseBody   []byte    `json:"response_body" db:"response_body"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt      time.Time `json:"expires_at" db:"expires_at"`
+	// Identifies current reservation of the key.
+	Reservation int64 `json:"-" db:"reservation"`
+}
+
+var ErrReservationExpired = errors.New("idempotency key reservation expired")
+
+// Reserves idempotency key of a caller for a new request. If the key is already
+// taken by a request that hasn't expired yet, stored key is returned with reserved
+// set to false. Response of a reserved key is empty until CompleteIdempotencyKey
+// is called, and the reservation expires after lease, so that a request that
+// never completed, e.g. because the service crashed, can be retried. Reservation
+// of the returned key must be passed to CompleteIdempotencyKey or
+// ReleaseIdempotencyKey.
+func (store *Store) ReserveIdempotencyKey(ctx context.Context, subject, key, requestHash string, lease time.Duration) (stored IdempotencyKey, reserved bool, err error) {
+	ctx, end := observe(ctx, "ReserveIdempotencyKey")
+	defer end()
+
+	const query = `
+	INSERT INTO "idempotency_keys"("subject", "idempotency_key", "request_hash", "expires_at")
+	VALUES ($1, $2, $3, now() + $4 * INTERVAL '1 millisecond')
+	ON CONFLICT ("subject", "idempotency_key") DO UPDATE
+	SET "request_hash" = EXCLUDED."request_hash",
+		"response_status" = 0,
+		"response_body" = NULL,
+		"created_at" = now(),
+		"expires_at" = EXCLUDED."expires_at",
+		"reservation" = "idempotency_keys"."reservation" + 1
+	WHERE "idempotency_keys"."expires_at" < now()
+	RETURNING *
+	`
+	err = store.db.GetContext(ctx, &stored, query, subject, key, requestHash, lease.Milliseconds())
+	if err == nil {
+		return stored, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+
+	// Key is used by another request.
+	const selectQuery = `SELECT * FROM "idempotency_keys" WHERE "subject" = $1 AND "idempotency_key" = $2`
+	err = store.db.GetContext(ctx, &stored, selectQuery, subject, key)
+
+	return stored, false, err
+}
+
+// Stores response of a request, so it can be replayed on retries until ttl passes.
+// Returns ErrReservationExpired when the key was reserved again by another request
+// after lease of this one ran out.
+func (store *Store) CompleteIdempotencyKey(ctx context.Context, subject, key string, reservation int64, status int, body []byte, ttl time.Duration) error {
+	ctx, end := observe(ctx, "CompleteIdempotencyKey")
+	defer end()
+
+	const query = `
+	UPDATE "idempotency_keys"
+	SET "response_status" = $3,
+		"response_body" = $4,
+		"expires_at" = now() + $5 * INTERVAL '1 millisecond'
+	WHERE "subject" = $1 AND "idempotency_key" = $2 AND "reservation" = $6 AND "response_status" = 0
+	`
+	result, err := store.db.ExecContext(ctx, query, subject, key, status, body, ttl.Milliseconds(), reservation)
+	if err != nil {
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err == nil && updated == 0 {
+		err = ErrReservationExpired
+	}
+
+	return err
+}
+
+// Releases reserved key of a failed request, so that the request can be retried.
+// Key reserved again by another request is kept.
+func (store *Store) ReleaseIdempotencyKey(ctx context.Context, subject, key string, reservation int64) error {
+	ctx, end := observe(ctx, "ReleaseIdempotencyKey")
+	defer end()
+
+	const query = `
+	DELETE FROM "idempotency_keys"
+	WHERE "subject" = $1 AND "idempotency_key" = $2 AND "reservation" = $3 AND "response_status" = 0
+	`
+	_, err := store.db.ExecContext(ctx, query, subject, key, reservation)
+
+	return err
+}
+
+// Deletes expired keys and returns how many were deleted.
+func (store *Store) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	ctx, end := observe(ctx, "DeleteExpiredIdempotencyKeys")
+	defer end()
+
+	const query = `DELETE FROM "idempotency_keys" WHERE "expires_at" < now()`
+	result, err := store.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"net/http"
+	"rating-service/util"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserveIdempotencyKey(t *testing.T) {
+	subject := util.RandomString(16)
+	key := util.RandomString(32)
+	hash := util.RandomString(64)
+
+	stored, reserved, err := testStore.ReserveIdempotencyKey(context.Background(), subject, key, hash, time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved)
+	require.Equal(t, subject, stored.Subject)
+	require.Equal(t, key, stored.Key)
+	require.Equal(t, hash, stored.RequestHash)
+	require.Zero(t, stored.ResponseStatus)
+
+	// Second reservation returns the pending key.
+	stored, reserved, err = testStore.ReserveIdempotencyKey(context.Background(), subject, key, hash, time.Hour)
+	require.NoError(t, err)
+	require.False(t, reserved)
+	require.Zero(t, stored.ResponseStatus)
+
+	body := []byte(`{"rating_id":1}`)
+	require.NoError(t, testStore.CompleteIdempotencyKey(context.Background(), subject, key, stored.Reservation, http.StatusCreated, body, time.Hour))
+
+	stored, reserved, err = testStore.ReserveIdempotencyKey(context.Background(), subject, key, util.RandomString(64), time.Hour)
+	require.NoError(t, err)
+	require.False(t, reserved)
+	require.Equal(t, hash, stored.RequestHash)
+	require.Equal(t, http.StatusCreated, stored.ResponseStatus)
+	require.Equal(t, body, stored.ResponseBody)
+
+	// Another caller can use the same key.
+	stored, reserved, err = testStore.ReserveIdempotencyKey(context.Background(), util.RandomString(16), key, hash, time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved)
+	require.Zero(t, stored.ResponseStatus)
+}
+
+func TestReserveExpiredIdempotencyKey(t *testing.T) {
+	subject := util.RandomString(16)
+	key := util.RandomString(32)
+
+	// Lease of a request that never completed runs out.
+	expired, reserved, err := testStore.ReserveIdempotencyKey(context.Background(), subject, key, util.RandomString(64), -time.Minute)
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	// Expired keys can be reserved again.
+	hash := util.RandomString(64)
+	stored, reserved, err := testStore.ReserveIdempotencyKey(context.Background(), subject, key, hash, time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved)
+	require.Equal(t, hash, stored.RequestHash)
+	require.NotEqual(t, expired.Reservation, stored.Reservation)
+
+	// Late request whose lease ran out can neither complete nor release the key.
+	err = testStore.CompleteIdempotencyKey(context.Background(), subject, key, expired.Reservation, http.StatusCreated, []byte(`{}`), time.Hour)
+	require.ErrorIs(t, err, ErrReservationExpired)
+	require.NoError(t, testStore.ReleaseIdempotencyKey(context.Background(), subject, key, expired.Reservation))
+
+	pending, reserved, err := testStore.ReserveIdempotencyKey(context.Background(), subject, key, hash, time.Hour)
+	require.NoError(t, err)
+	require.False(t, reserved)
+	require.Equal(t, hash, pending.RequestHash)
+	require.Zero(t, pending.ResponseStatus)
+
+	// Completed key expires after ttl instead of lease.
+	require.NoError(t, testStore.CompleteIdempotencyKey(context.Background(), subject, key, stored.Reservation, http.StatusCreated, nil, -time.Minute))
+	deleted, err := testStore.DeleteExpiredIdempotencyKeys(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	_, reserved, err = testStore.ReserveIdempotencyKey(context.Background(), subject, key, hash, time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved)
+}
+
+func TestReleaseIdempotencyKey(t *testing.T) {
+	subject := util.RandomString(16)
+	key := util.RandomString(32)
+
+	stored, reserved, err := testStore.ReserveIdempotencyKey(context.Background(), subject, key, util.RandomString(64), time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	require.NoError(t, testStore.ReleaseIdempotencyKey(context.Background(), subject, key, stored.Reservation))
+
+	_, reserved, err = testStore.ReserveIdempotencyKey(context.Background(), subject, key, util.RandomString(64), time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved)
+}
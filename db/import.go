@@ -0,0 +1,276 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"rating-service/metrics"
+	"rating-service/similarity"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Number of rows copied at once.
+const importBatchSize = 1000
+
+type ImportRatingParam struct {
+	Station_id int64     `json:"station_id"`
+	User_id    int64     `json:"user_id"`
+	Rating     int64     `json:"rating"`
+	Comment    string    `json:"comment"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Single row of an import. Err is set when the row couldn't be decoded.
+type ImportRecord struct {
+	Row    int
+	Rating ImportRatingParam
+	Err    error
+}
+
+// Stream of rows to import. Next returns io.EOF when there are no more rows.
+type ImportSource interface {
+	Next() (ImportRecord, error)
+}
+
+type RowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+type ImportReport struct {
+	DryRun   bool       `json:"dry_run"`
+	Total    int        `json:"total"`
+	Imported int        `json:"imported"`
+	Failed   int        `json:"failed"`
+	Errors   []RowError `json:"errors"`
+}
+
+// Checks if rating satisfies constraints of ratings table.
+func (arg ImportRatingParam) Validate() error {
+	// Columns are INT, a larger value would fail the copy of the whole batch.
+	if arg.Station_id < 1 || arg.Station_id > math.MaxInt32 {
+		return fmt.Errorf("station_id must be a positive number up to %d", math.MaxInt32)
+	}
+	if arg.User_id < 1 || arg.User_id > math.MaxInt32 {
+		return fmt.Errorf("user_id must be a positive number up to %d", math.MaxInt32)
+	}
+	if arg.Rating < 1 || arg.Rating > 5 {
+		return fmt.Errorf("rating must be between 1 and 5")
+	}
+	if utf8.RuneCountInString(arg.Comment) > 256 {
+		return fmt.Errorf("comment must be at most 256 characters long")
+	}
+
+	return nil
+}
+
+// Valid row of an import that waits to be copied.
+type importRow struct {
+	id        int64
+	arg       ImportRatingParam
+	comment   moderatedComment
+	signature similarity.Signature
+}
+
+// Returns given number of new rating IDs.
+func nextRatingIDs(ctx context.Context, tx *sqlx.Tx, count int) (ids []int64, err error) {
+	const query = `SELECT nextval(pg_get_serial_sequence('ratings', 'rating_id')) FROM generate_series(1, $1)`
+	err = tx.SelectContext(ctx, &ids, query, count)
+
+	return
+}
+
+// Streams rows into ratings table and signatures of their comments into
+// comment_signatures table with COPY.
+func copyRatings(ctx context.Context, tx *sqlx.Tx, rows []importRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("ratings", "rating_id", "station_id", "user_id", "rating", "comment",
+		"created_at", "status", "moderation_reasons", "sentiment"))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		// COPY would encode bytes of JSON as bytea.
+		reasons, err := json.Marshal(row.comment.Reasons)
+		if err != nil {
+			return err
+		}
+
+		_, err = stmt.ExecContext(ctx, row.id, row.arg.Station_id, row.arg.User_id, row.arg.Rating, row.comment.Comment,
+			row.arg.CreatedAt, row.comment.Status, string(reasons), commentSentiment(row.comment.Comment))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Flush buffered rows.
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return err
+	}
+	if err := stmt.Close(); err != nil {
+		return err
+	}
+
+	stmt, err = tx.PrepareContext(ctx, pq.CopyIn("comment_signatures", "rating_id", "user_id", "signature", "bands"))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, row := range rows {
+		if row.signature == nil {
+			continue
+		}
+		if _, err := stmt.ExecContext(ctx, row.id, row.arg.User_id, pq.Int64Array(row.signature), pq.Int64Array(row.signature.Bands())); err != nil {
+			return err
+		}
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return err
+	}
+
+	return stmt.Close()
+}
+
+/// Import godoc
+// @Summary      Import ratings from CSV or NDJSON
+// @Description  bulk import ratings, comments are checked by content filters, rows that fail validation or are rejected are reported and skipped
+// @ID           import-ratings
+// @Tags         ratings
+// @Accept       plain
+// @Produce      json
+// @Param        format   query      string  true  "Format of request body (csv or ndjson)"
+// @Param        dry_run   query      bool  false  "Validate and roll back without importing"
+// @Success      200  {object}  ImportReport
+// @Failure      400  {object}  HTTPError400
+// @Failure      500  {object}  HTTPError500
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /ratings/import [post]
+func (store *Store) Import(ctx context.Context, source ImportSource, dryRun bool) (report ImportReport, err error) {
+	ctx, end := observe(ctx, "Import")
+	defer end()
+
+	report.DryRun = dryRun
+	report.Errors = []RowError{}
+
+	tx, err := store.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	// Stations with imported ratings and number of imported ratings by stars.
+	var stationIDs []int64
+	imported := make(map[int64]bool)
+	stars := make(map[int64]int)
+
+	// Valid rows are copied in batches. Their IDs are allocated in advance, so that
+	// signatures of comments can be copied with them.
+	var batch []importRow
+	var unstored []commentSignature
+	var ids []int64
+
+	for {
+		record, err := source.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return report, err
+		}
+
+		report.Total++
+
+		if record.Err == nil {
+			record.Err = record.Rating.Validate()
+		}
+
+		// Check comment like when a rating is created, comparing it also with
+		// earlier rows of the import.
+		var comment moderatedComment
+		var signature similarity.Signature
+		if record.Err == nil {
+			if comment, err = store.moderate(record.Rating.Comment); err == nil {
+				signature, err = store.checkDuplicates(ctx, tx, 0, record.Rating.User_id, &comment, unstored)
+			}
+
+			var rejected *RejectedError
+			if errors.As(err, &rejected) {
+				record.Err = err
+			} else if err != nil {
+				return report, err
+			}
+		}
+
+		if record.Err != nil {
+			report.Failed++
+			report.Errors = append(report.Errors, RowError{Row: record.Row, Message: record.Err.Error()})
+			continue
+		}
+
+		if len(ids) == 0 {
+			if ids, err = nextRatingIDs(ctx, tx, importBatchSize); err != nil {
+				return report, err
+			}
+		}
+
+		row := importRow{id: ids[0], arg: record.Rating, comment: comment, signature: signature}
+		if row.arg.CreatedAt.IsZero() {
+			row.arg.CreatedAt = time.Now()
+		}
+		ids = ids[1:]
+
+		batch = append(batch, row)
+		if signature != nil {
+			unstored = append(unstored, commentSignature{RatingID: row.id, UserID: row.arg.User_id, Signature: pq.Int64Array(signature)})
+		}
+
+		report.Imported++
+		stars[row.arg.Rating]++
+
+		if !imported[row.arg.Station_id] {
+			imported[row.arg.Station_id] = true
+			stationIDs = append(stationIDs, row.arg.Station_id)
+		}
+
+		if len(batch) == importBatchSize {
+			if err := copyRatings(ctx, tx, batch); err != nil {
+				return report, err
+			}
+			batch, unstored = batch[:0], unstored[:0]
+		}
+	}
+
+	if err = copyRatings(ctx, tx, batch); err != nil {
+		return
+	}
+
+	if dryRun {
+		return report, tx.Rollback()
+	}
+
+	if err = tx.Commit(); err != nil {
+		return
+	}
+
+	for rating, count := range stars {
+		metrics.RatingsCreated.WithLabelValues(strconv.FormatInt(rating, 10)).Add(float64(count))
+	}
+	store.notifyWrite(ctx, stationIDs...)
+	return
+}
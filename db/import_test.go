@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"rating-service/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type sliceSource struct {
+	records []ImportRecord
+}
+
+func (s *sliceSource) Next() (ImportRecord, error) {
+	if len(s.records) == 0 {
+		return ImportRecord{}, io.EOF
+	}
+
+	record := s.records[0]
+	s.records = s.records[1:]
+	return record, nil
+}
+
+func randomImportSource(stationID int64) *sliceSource {
+	return &sliceSource{records: []ImportRecord{
+		{Row: 2, Rating: ImportRatingParam{Station_id: stationID, User_id: util.RandomInt(1, 1000), Rating: util.RandomInt(1, 5), Comment: util.RandomString(10)}},
+		{Row: 3, Rating: ImportRatingParam{Station_id: stationID, User_id: util.RandomInt(1, 1000), Rating: 7}},
+		{Row: 4, Err: errors.New("malformed row")},
+		{Row: 5, Rating: ImportRatingParam{Station_id: stationID, User_id: util.RandomInt(1, 1000), Rating: util.RandomInt(1, 5)}},
+	}}
+}
+
+func TestImportRatings(t *testing.T) {
+	stationID := util.RandomInt(1000000, 2000000)
+
+	report, err := testStore.Import(context.Background(), randomImportSource(stationID), false)
+	require.NoError(t, err)
+
+	require.False(t, report.DryRun)
+	require.Equal(t, 4, report.Total)
+	require.Equal(t, 2, report.Imported)
+	require.Equal(t, 2, report.Failed)
+	require.Len(t, report.Errors, 2)
+	require.Equal(t, 3, report.Errors[0].Row)
+	require.Equal(t, 4, report.Errors[1].Row)
+
+	ratings, err := testStore.GetAllByStation(context.Background(), stationID)
+	require.NoError(t, err)
+	require.Len(t, ratings, 2)
+}
+
+func TestImportRatingsDryRun(t *testing.T) {
+	stationID := util.RandomInt(1000000, 2000000)
+
+	report, err := testStore.Import(context.Background(), randomImportSource(stationID), true)
+	require.NoError(t, err)
+
+	require.True(t, report.DryRun)
+	require.Equal(t, 2, report.Imported)
+	require.Equal(t, 2, report.Failed)
+
+	ratings, err := testStore.GetAllByStation(context.Background(), stationID)
+	require.NoError(t, err)
+	require.Empty(t, ratings)
+}
+
+func TestImportRatingParamValidate(t *testing.T) {
+	arg := ImportRatingParam{Station_id: math.MaxInt32, User_id: 1, Rating: 5}
+	require.NoError(t, arg.Validate())
+
+	arg.Station_id = math.MaxInt32 + 1
+	require.EqualError(t, arg.Validate(), "station_id must be a positive number up to 2147483647")
+
+	arg.Station_id = 1
+	arg.User_id = math.MaxInt32 + 1
+	require.EqualError(t, arg.Validate(), "user_id must be a positive number up to 2147483647")
+}
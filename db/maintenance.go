@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"rating-service/metrics"
+	"time"
+)
+
+// Returned when purge would delete all ratings.
+var ErrEmptyPurgeFilter = errors.New("purge requires station, user or time filter")
+
+type PurgeParam struct {
+	StationID int64
+	UserID    int64
+	Before    time.Time
+	DryRun    bool
+}
+
+type PurgeReport struct {
+	DryRun   bool            `json:"dry_run"`
+	Deleted  int64           `json:"deleted"`
+	Stations map[int64]int64 `json:"stations"`
+}
+
+// Deletes ratings matching all given filters. Dry run counts matching ratings and rolls back.
+func (store *Store) Purge(ctx context.Context, arg PurgeParam) (report PurgeReport, err error) {
+	ctx, end := observe(ctx, "Purge")
+	defer end()
+
+	if arg.StationID == 0 && arg.UserID == 0 && arg.Before.IsZero() {
+		return report, ErrEmptyPurgeFilter
+	}
+
+	const query = `
+	WITH "deleted" AS (
+		DELETE FROM "ratings"
+		WHERE ($1 = 0 OR "station_id" = $1)
+			AND ($2 = 0 OR "user_id" = $2)
+			AND ($3::TIMESTAMP IS NULL OR "created_at" < $3)
+		RETURNING "station_id"
+	)
+	SELECT "station_id", COUNT(*) AS "count"
+	FROM "deleted"
+	GROUP BY "station_id"
+	`
+
+	tx, err := store.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return
+	}
+	defer tx.Rollback()
+
+	var rows []struct {
+		StationID int64 `db:"station_id"`
+		Count     int64 `db:"count"`
+	}
+	if err = tx.SelectContext(ctx, &rows, query, arg.StationID, arg.UserID, nullTime(arg.Before)); err != nil {
+		return
+	}
+
+	report.DryRun = arg.DryRun
+	report.Stations = make(map[int64]int64, len(rows))
+
+	stationIDs := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		report.Deleted += row.Count
+		report.Stations[row.StationID] = row.Count
+		stationIDs = append(stationIDs, row.StationID)
+	}
+
+	if arg.DryRun {
+		return report, tx.Rollback()
+	}
+
+	if err = tx.Commit(); err != nil {
+		return
+	}
+
+	metrics.RatingsDeleted.Add(float64(report.Deleted))
+	store.notifyWrite(ctx, stationIDs...)
+	return
+}
+
+// Returns IDs of all stations with ratings.
+func (store *Store) StationIDs(ctx context.Context) (stationIDs []int64, err error) {
+	ctx, end := observe(ctx, "StationIDs")
+	defer end()
+
+	const query = `SELECT DISTINCT "station_id" FROM "ratings" ORDER BY "station_id"`
+	stationIDs = []int64{}
+	err = store.db.SelectContext(ctx, &stationIDs, query)
+
+	return
+}
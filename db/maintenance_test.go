@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"rating-service/util"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurge(t *testing.T) {
+	ctx := context.Background()
+	userID := util.RandomInt(1000000, 2000000)
+
+	var stationIDs []int64
+	for i := 0; i < 3; i++ {
+		stars := util.RandomStars()
+		rating, err := testStore.Create(ctx, CreateRatingParam{
+			Station_id: util.RandomInt(1, 1000),
+			User_id:    userID,
+			Rating:     stars,
+			Comment:    util.RandomComment(stars),
+		})
+		require.NoError(t, err)
+		stationIDs = append(stationIDs, rating.Station_id)
+	}
+
+	// Dry run only counts ratings.
+	report, err := testStore.Purge(ctx, PurgeParam{UserID: userID, DryRun: true})
+	require.NoError(t, err)
+	require.True(t, report.DryRun)
+	require.Equal(t, int64(3), report.Deleted)
+
+	report, err = testStore.Purge(ctx, PurgeParam{UserID: userID})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), report.Deleted)
+	for _, stationID := range stationIDs {
+		require.Contains(t, report.Stations, stationID)
+	}
+
+	report, err = testStore.Purge(ctx, PurgeParam{UserID: userID, Before: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	require.Zero(t, report.Deleted)
+}
+
+func TestPurgeWithoutFilter(t *testing.T) {
+	_, err := testStore.Purge(context.Background(), PurgeParam{DryRun: true})
+	require.ErrorIs(t, err, ErrEmptyPurgeFilter)
+}
+
+func TestStationIDs(t *testing.T) {
+	rating := createRandomRating(t)
+
+	stationIDs, err := testStore.StationIDs(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, stationIDs, rating.Station_id)
+}
@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"go.uber.org/zap"
+)
+
+//go:embed migration/*.sql
+var migrations embed.FS
+
+// Version of database schema the service expects, that is version of the latest embedded migration.
+var SchemaVersion = latestMigration()
+
+// State of database schema.
+type MigrationStatus struct {
+	Version  int64 `json:"version"`
+	Expected int64 `json:"expected"`
+	Dirty    bool  `json:"dirty"`
+}
+
+// Applies migrations embedded in the binary. Migrations hold a Postgres advisory
+// lock while they run, so replicas that start at the same time wait for each other.
+type Migrator struct {
+	migrate *migrate.Migrate
+}
+
+// Creates migrator with its own connection to database at given URL.
+func NewMigrator(source string) (*Migrator, error) {
+	files, err := iofs.New(migrations, "migration")
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", files, source)
+	if err != nil {
+		return nil, err
+	}
+	m.Log = migrationLogger{}
+
+	return &Migrator{migrate: m}, nil
+}
+
+// Applies all pending migrations.
+func (m *Migrator) Up() error {
+	err := m.migrate.Up()
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+
+	return err
+}
+
+// Reverts given number of applied migrations.
+func (m *Migrator) Down(steps int) error {
+	err := m.migrate.Steps(-steps)
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+
+	return err
+}
+
+// Returns version of applied migrations.
+func (m *Migrator) Status() (status MigrationStatus, err error) {
+	status.Expected = SchemaVersion
+
+	version, dirty, err := m.migrate.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return status, nil
+	}
+	if err != nil {
+		return
+	}
+
+	status.Version = int64(version)
+	status.Dirty = dirty
+	return
+}
+
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.migrate.Close()
+	if sourceErr != nil {
+		return sourceErr
+	}
+
+	return dbErr
+}
+
+// Returns version of applied migrations and whether the last migration failed halfway.
+func (store *Store) MigrationVersion(ctx context.Context) (version int64, dirty bool, err error) {
+	const query = `SELECT "version", "dirty" FROM "schema_migrations" LIMIT 1`
+
+	row := store.db.QueryRowxContext(ctx, query)
+	err = row.Scan(&version, &dirty)
+
+	return
+}
+
+// Finds version of the latest embedded migration from names of files.
+func latestMigration() int64 {
+	entries, err := fs.ReadDir(migrations, "migration")
+	if err != nil {
+		panic(err)
+	}
+
+	var latest int64
+	for _, entry := range entries {
+		prefix := strings.SplitN(entry.Name(), "_", 2)[0]
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			panic("invalid migration file name " + entry.Name())
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+
+	return latest
+}
+
+// Writes progress of migrations to service log.
+type migrationLogger struct{}
+
+func (migrationLogger) Printf(format string, v ...interface{}) {
+	zap.S().Infof(strings.TrimSuffix(format, "\n"), v...)
+}
+
+func (migrationLogger) Verbose() bool {
+	return false
+}
@@ -0,0 +1,10 @@
+CREATE TABLE "idempotency_keys" (
+    "idempotency_key"   VARCHAR(255) PRIMARY KEY,
+    "request_hash"      VARCHAR(64) NOT NULL,
+    "response_status"   INT NOT NULL DEFAULT 0,
+    "response_body"     BYTEA,
+    "created_at"        TIMESTAMP NOT NULL DEFAULT(now()),
+    "expires_at"        TIMESTAMP NOT NULL
+);
+
+CREATE INDEX ON "idempotency_keys" ("expires_at");
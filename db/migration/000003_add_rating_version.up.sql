@@ -0,0 +1 @@
+ALTER TABLE "ratings" ADD COLUMN "version" BIGINT NOT NULL DEFAULT 1;
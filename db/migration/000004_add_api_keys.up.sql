@@ -0,0 +1,10 @@
+CREATE TABLE "api_keys" (
+    "api_key_id"    BIGSERIAL PRIMARY KEY,
+    "name"          VARCHAR(64) NOT NULL,
+    "prefix"        VARCHAR(16) NOT NULL UNIQUE,
+    "key_hash"      VARCHAR(64) NOT NULL,
+    "scopes"        TEXT[] NOT NULL,
+    "created_at"    TIMESTAMP NOT NULL DEFAULT(now()),
+    "rotated_at"    TIMESTAMP,
+    "revoked_at"    TIMESTAMP
+);
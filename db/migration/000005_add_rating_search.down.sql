@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "ratings_search_vector_idx";
+ALTER TABLE "ratings" DROP COLUMN IF EXISTS "search_vector";
+DROP TEXT SEARCH CONFIGURATION IF EXISTS "slovenian_unaccent";
+DROP EXTENSION IF EXISTS "unaccent";
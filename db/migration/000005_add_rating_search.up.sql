@@ -0,0 +1,16 @@
+-- Slovenian has no stemmer in Postgres, so words are only lower cased and stripped
+-- of accents, which also matches comments written without č, š and ž.
+CREATE EXTENSION IF NOT EXISTS "unaccent";
+
+CREATE TEXT SEARCH CONFIGURATION "slovenian_unaccent" (COPY = "simple");
+ALTER TEXT SEARCH CONFIGURATION "slovenian_unaccent"
+    ALTER MAPPING FOR "hword", "hword_part", "word" WITH "unaccent", "simple";
+
+-- Vector holds lexemes of every config, so a query in any of them matches.
+ALTER TABLE "ratings" ADD COLUMN "search_vector" TSVECTOR GENERATED ALWAYS AS (
+    to_tsvector('simple'::regconfig, COALESCE("comment", '')) ||
+    to_tsvector('english'::regconfig, COALESCE("comment", '')) ||
+    to_tsvector('slovenian_unaccent'::regconfig, COALESCE("comment", ''))
+) STORED;
+
+CREATE INDEX "ratings_search_vector_idx" ON "ratings" USING GIN ("search_vector");
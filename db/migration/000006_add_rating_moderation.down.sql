@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "ratings_pending_idx";
+ALTER TABLE "ratings" DROP COLUMN IF EXISTS "moderation_reasons";
+ALTER TABLE "ratings" DROP COLUMN IF EXISTS "status";
@@ -0,0 +1,5 @@
+ALTER TABLE "ratings" ADD COLUMN "status" VARCHAR(16) NOT NULL DEFAULT 'published'
+    CHECK ("status" IN ('published', 'pending', 'removed'));
+ALTER TABLE "ratings" ADD COLUMN "moderation_reasons" JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX "ratings_pending_idx" ON "ratings" ("created_at") WHERE "status" = 'pending';
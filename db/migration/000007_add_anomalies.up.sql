@@ -0,0 +1,19 @@
+CREATE TABLE "anomalies" (
+    "anomaly_id"        BIGSERIAL PRIMARY KEY,
+    "station_id"        BIGINT NOT NULL,
+    "window_start"      TIMESTAMP NOT NULL,
+    "window_end"        TIMESTAMP NOT NULL,
+    "rating_count"      BIGINT NOT NULL,
+    "expected_count"    DOUBLE PRECISION NOT NULL,
+    "average_rating"    DOUBLE PRECISION NOT NULL,
+    "baseline_average"  DOUBLE PRECISION NOT NULL,
+    "distance"          DOUBLE PRECISION NOT NULL,
+    "frozen"            BOOLEAN NOT NULL DEFAULT FALSE,
+    "created_at"        TIMESTAMP NOT NULL DEFAULT(now()),
+    "resolved_at"       TIMESTAMP
+);
+
+-- A station has at most one open anomaly, which is extended while it lasts.
+CREATE UNIQUE INDEX "anomalies_open_station_idx" ON "anomalies" ("station_id") WHERE "resolved_at" IS NULL;
+
+CREATE INDEX "ratings_created_at_idx" ON "ratings" ("created_at");
@@ -0,0 +1,11 @@
+CREATE TABLE "comment_signatures" (
+    "rating_id"   BIGINT PRIMARY KEY REFERENCES "ratings" ("rating_id") ON DELETE CASCADE,
+    "user_id"     BIGINT NOT NULL,
+    "signature"   BIGINT[] NOT NULL,
+    "bands"       BIGINT[] NOT NULL,
+    "created_at"  TIMESTAMP NOT NULL DEFAULT(now())
+);
+
+-- Candidates for comparison share at least one band with the new comment.
+CREATE INDEX "comment_signatures_bands_idx" ON "comment_signatures" USING GIN ("bands");
+CREATE INDEX "comment_signatures_user_id_idx" ON "comment_signatures" ("user_id");
@@ -0,0 +1 @@
+ALTER TABLE "ratings" ADD COLUMN "sentiment" DOUBLE PRECISION;
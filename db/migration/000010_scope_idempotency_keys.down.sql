@@ -0,0 +1,4 @@
+DELETE FROM "idempotency_keys" WHERE "subject" <> '';
+ALTER TABLE "idempotency_keys" DROP CONSTRAINT "idempotency_keys_pkey";
+ALTER TABLE "idempotency_keys" ADD PRIMARY KEY ("idempotency_key");
+ALTER TABLE "idempotency_keys" DROP COLUMN IF EXISTS "subject";
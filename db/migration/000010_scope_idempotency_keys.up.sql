@@ -0,0 +1,5 @@
+-- Keys are scoped by authenticated caller, so that one caller can't replay
+-- responses stored for another.
+ALTER TABLE "idempotency_keys" ADD COLUMN "subject" VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE "idempotency_keys" DROP CONSTRAINT "idempotency_keys_pkey";
+ALTER TABLE "idempotency_keys" ADD PRIMARY KEY ("subject", "idempotency_key");
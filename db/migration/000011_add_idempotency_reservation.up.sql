@@ -0,0 +1,3 @@
+-- Reservation changes every time an expired key is reserved again, so that a
+-- request whose lease ran out can't complete or release the key of the next one.
+ALTER TABLE "idempotency_keys" ADD COLUMN "reservation" BIGINT NOT NULL DEFAULT 1;
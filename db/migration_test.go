@@ -0,0 +1,15 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationVersion(t *testing.T) {
+	version, dirty, err := testStore.MigrationVersion(context.Background())
+	require.NoError(t, err)
+	require.False(t, dirty)
+	require.Equal(t, SchemaVersion, version)
+}
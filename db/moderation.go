@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"rating-service/metrics"
+	"rating-service/moderation"
+)
+
+// Ratings are public only while they are published.
+const (
+	StatusPublished = "published"
+	StatusPending   = "pending"
+	StatusRemoved   = "removed"
+)
+
+// Reasons why content filters masked a comment or sent it to moderation.
+type ModerationReasons []moderation.Reason
+
+func (r ModerationReasons) Value() (driver.Value, error) {
+	if r == nil {
+		r = ModerationReasons{}
+	}
+
+	return json.Marshal(r)
+}
+
+func (r *ModerationReasons) Scan(src interface{}) error {
+	switch data := src.(type) {
+	case []byte:
+		return json.Unmarshal(data, r)
+	case string:
+		return json.Unmarshal([]byte(data), r)
+	case nil:
+		*r = ModerationReasons{}
+		return nil
+	default:
+		return fmt.Errorf("unsupported type %T of moderation reasons", src)
+	}
+}
+
+// Returned when content filters reject a comment.
+type RejectedError struct {
+	Reasons ModerationReasons
+}
+
+func (e *RejectedError) Error() string {
+	return "comment was rejected: " + moderation.Result{Reasons: e.Reasons}.String()
+}
+
+// Comment after content filters were applied.
+type moderatedComment struct {
+	Comment string
+	Status  string
+	Reasons ModerationReasons
+}
+
+// Sets pipeline that checks comments of created and changed ratings before they
+// are stored. It must be set before the store is used.
+func (store *Store) SetContentFilter(pipeline *moderation.Pipeline) {
+	store.filter = pipeline
+}
+
+// Runs comment through content filters. Returns RejectedError when it must not be stored.
+func (store *Store) moderate(comment string) (moderatedComment, error) {
+	if store.filter == nil {
+		return moderatedComment{Comment: comment, Status: StatusPublished, Reasons: ModerationReasons{}}, nil
+	}
+
+	result := store.filter.Check(comment)
+	if result.Action != moderation.ActionNone {
+		metrics.ModerationActions.WithLabelValues(string(result.Action)).Inc()
+	}
+
+	moderated := moderatedComment{Comment: result.Comment, Status: StatusPublished, Reasons: result.Reasons}
+	switch result.Action {
+	case moderation.ActionReject:
+		return moderated, &RejectedError{Reasons: result.Reasons}
+	case moderation.ActionModerate:
+		moderated.Status = StatusPending
+	}
+
+	return moderated, nil
+}
+
+/// GetPending godoc
+// @Summary      Get ratings waiting for moderation
+// @Description  get pending ratings, oldest first
+// @ID           get-pending-ratings
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        offset   query      int  false  "Offset"
+// @Param        limit   query      int  true  "Limit"
+// @Success      200  {object}  []Rating
+// @Failure      400  {object}  HTTPError400
+// @Failure      500  {object}  HTTPError500
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /moderation/ratings [get]
+func (store *Store) GetPending(ctx context.Context, arg ListRatingParam) (ratings []Rating, err error) {
+	ctx, end := observe(ctx, "GetPending")
+	defer end()
+
+	const query = `
+	SELECT ` + ratingColumns + ` FROM "ratings"
+	WHERE "status" = 'pending'
+	ORDER BY "created_at", "rating_id"
+	OFFSET $1 LIMIT $2
+	`
+	ratings = []Rating{}
+	err = store.db.SelectContext(ctx, &ratings, query, arg.Offset, arg.Limit)
+
+	return
+}
+
+/// Moderate godoc
+// @Summary      Approve or remove a rating
+// @Description  approve publishes the rating, remove hides it from public reads
+// @Tags         moderation
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Rating ID"
+// @Success      200  {object}  Rating
+// @Failure      400  {object}  HTTPError400
+// @Failure      404  {object}  HTTPError404
+// @Failure      500  {object}  HTTPError500
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /moderation/ratings/{id}/approve [post]
+// @Router       /moderation/ratings/{id}/remove [post]
+func (store *Store) Moderate(ctx context.Context, id int64, status string) (rating Rating, err error) {
+	ctx, end := observe(ctx, "Moderate")
+	defer end()
+
+	action := map[string]string{StatusPublished: "approve", StatusRemoved: "remove"}[status]
+	if action == "" {
+		return rating, errors.New("status must be published or removed")
+	}
+
+	const query = `
+	UPDATE "ratings"
+	SET "status" = $2,
+		"version" = "version" + 1
+	WHERE "rating_id" = $1
+	RETURNING ` + ratingColumns
+	if err = store.db.GetContext(ctx, &rating, query, id, status); err != nil {
+		return
+	}
+
+	metrics.ModerationActions.WithLabelValues(action).Inc()
+	store.notifyWrite(ctx, rating.Station_id)
+	return
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"rating-service/config"
+	"rating-service/moderation"
+	"rating-service/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Returns store that checks comments with default content filters.
+func moderatedStore(t *testing.T) *Store {
+	settings, err := config.New("../.")
+	require.NoError(t, err)
+	settings.ModerationBlocklist = []string{"cheap energy"}
+
+	pipeline, err := moderation.New(settings)
+	require.NoError(t, err)
+
+	store, err := Connect(settings.DBDriver, settings.DBSource)
+	require.NoError(t, err)
+	store.SetContentFilter(pipeline)
+
+	return store
+}
+
+func TestCreateModeratedRating(t *testing.T) {
+	store := moderatedStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	arg := CreateRatingParam{
+		Station_id: util.RandomInt(1261, 654561),
+		User_id:    util.RandomInt(1261, 654561),
+		Rating:     1,
+	}
+
+	// Profanity is masked, rating stays public.
+	arg.Comment = "Shit charger."
+	masked, err := store.Create(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, "**** charger.", masked.Comment)
+	require.Equal(t, StatusPublished, masked.Status)
+	require.Len(t, masked.ModerationReasons, 1)
+	require.Equal(t, "profanity", masked.ModerationReasons[0].Filter)
+
+	// Links send rating to moderation, so it isn't public until approved.
+	arg.Comment = "Better one on www.example.com"
+	pending, err := store.Create(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, pending.Status)
+
+	ratings, err := store.GetAllByStation(ctx, arg.Station_id)
+	require.NoError(t, err)
+	require.Len(t, ratings, 1)
+	require.Equal(t, masked.ID, ratings[0].ID)
+
+	queue, err := store.GetPending(ctx, ListRatingParam{Limit: 1000})
+	require.NoError(t, err)
+	require.Contains(t, ids(queue), pending.ID)
+
+	approved, err := store.Moderate(ctx, pending.ID, StatusPublished)
+	require.NoError(t, err)
+	require.Equal(t, StatusPublished, approved.Status)
+	require.Equal(t, pending.Version+1, approved.Version)
+
+	summary, err := store.GetStationSummary(ctx, arg.Station_id)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), summary.RatingCount)
+
+	// Blocked phrases are never stored.
+	arg.Comment = "CHEAP ENERGY here"
+	_, err = store.Create(ctx, arg)
+	var rejected *RejectedError
+	require.ErrorAs(t, err, &rejected)
+	require.Equal(t, "blocklist", rejected.Reasons[0].Filter)
+}
+
+func TestPatchModeratedRating(t *testing.T) {
+	store := moderatedStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	rating := createRandomRating(t)
+
+	// Changed comment is checked again.
+	comment := "Call me on 041 123 456"
+	result, err := store.Patch(ctx, PatchRatingParam{Comment: &comment, Version: rating.Version}, rating.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Call me on ***********", result.Comment)
+
+	removed, err := store.Moderate(ctx, rating.ID, StatusRemoved)
+	require.NoError(t, err)
+	require.Equal(t, StatusRemoved, removed.Status)
+
+	// Status is kept when comment doesn't change.
+	stars := int64(5)
+	result, err = store.Patch(ctx, PatchRatingParam{Rating: &stars, Version: removed.Version}, rating.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusRemoved, result.Status)
+	require.Equal(t, "Call me on ***********", result.Comment)
+}
+
+func TestUpdateModeratedRating(t *testing.T) {
+	store := moderatedStore(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	arg := UpdateRatingParam{
+		Station_id: util.RandomInt(1261, 654561),
+		User_id:    util.RandomInt(1261, 654561),
+		Rating:     4,
+	}
+
+	// Published rating is held for moderation when changed comment needs it.
+	rating := createRandomRating(t)
+	arg.Comment = "Better one on www.example.com"
+	arg.Version = rating.Version
+	pending, err := store.Update(ctx, arg, rating.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, pending.Status)
+
+	// Clean comment doesn't publish a pending rating.
+	arg.Comment = "Fast and reliable."
+	arg.Version = pending.Version
+	result, err := store.Update(ctx, arg, rating.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, result.Status)
+	require.Equal(t, "link", result.ModerationReasons[0].Filter)
+
+	// Removed rating stays removed.
+	removed, err := store.Moderate(ctx, rating.ID, StatusRemoved)
+	require.NoError(t, err)
+	comment := "Works great now."
+	result, err = store.Patch(ctx, PatchRatingParam{Comment: &comment, Version: removed.Version}, rating.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusRemoved, result.Status)
+}
+
+func TestImportModeratedRatings(t *testing.T) {
+	store := moderatedStore(t)
+	defer store.Close()
+
+	stationID := util.RandomInt(1000000, 2000000)
+	source := &sliceSource{records: []ImportRecord{
+		{Row: 2, Rating: ImportRatingParam{Station_id: stationID, User_id: 1, Rating: 1, Comment: "Shit charger."}},
+		{Row: 3, Rating: ImportRatingParam{Station_id: stationID, User_id: 1, Rating: 1, Comment: "Better one on www.example.com"}},
+		{Row: 4, Rating: ImportRatingParam{Station_id: stationID, User_id: 1, Rating: 1, Comment: "CHEAP ENERGY here"}},
+	}}
+
+	report, err := store.Import(context.Background(), source, false)
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Imported)
+	require.Equal(t, 1, report.Failed)
+	require.Equal(t, 4, report.Errors[0].Row)
+	require.Contains(t, report.Errors[0].Message, "blocklist")
+
+	// Masked rating is public, the one with a link waits for moderation.
+	ratings, err := store.GetAllByStation(context.Background(), stationID)
+	require.NoError(t, err)
+	require.Len(t, ratings, 1)
+	require.Equal(t, "**** charger.", ratings[0].Comment)
+	require.Equal(t, "profanity", ratings[0].ModerationReasons[0].Filter)
+}
+
+func ids(ratings []Rating) []int64 {
+	result := make([]int64, len(ratings))
+	for i, rating := range ratings {
+		result[i] = rating.ID
+	}
+
+	return result
+}
@@ -2,9 +2,18 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"rating-service/metrics"
+	"strconv"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+// Columns of Rating, search vector is only used for filtering.
+const ratingColumns = `"rating_id", "station_id", "user_id", "rating", "comment", "created_at", "version", "status", "moderation_reasons", "sentiment"`
+
 type Rating struct {
 	ID         int64     `json:"rating_id" db:"rating_id"`
 	Station_id int64     `json:"station_id" db:"station_id"`
@@ -12,8 +21,23 @@ type Rating struct {
 	Rating     int64     `json:"rating" db:"rating"`
 	Comment    string    `json:"comment" db:"comment"`
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	Version    int64     `json:"version" db:"version"`
+	Status     string    `json:"status" db:"status"`
+	// Why content filters masked comment or sent rating to moderation.
+	ModerationReasons ModerationReasons `json:"moderation_reasons" db:"moderation_reasons"`
+	// Sentiment of comment from -1 to 1, null when comment has no known words.
+	Sentiment *float64 `json:"sentiment" db:"sentiment"`
+}
+
+// Rating returned by updates together with station it belonged to before.
+type updatedRating struct {
+	Rating
+	OldStationID int64 `db:"old_station_id"`
 }
 
+// Returned when a rating was modified since the version client expects.
+var ErrVersionMismatch = errors.New("rating version mismatch")
+
 type CreateRatingParam struct {
 	Station_id int64
 	User_id    int64
@@ -26,6 +50,15 @@ type UpdateRatingParam struct {
 	User_id    int64
 	Rating     int64
 	Comment    string
+	Version    int64 `json:"-"`
+}
+
+type PatchRatingParam struct {
+	Station_id *int64  `json:"station_id"`
+	User_id    *int64  `json:"user_id"`
+	Rating     *int64  `json:"rating"`
+	Comment    *string `json:"comment"`
+	Version    int64   `json:"-"`
 }
 
 type ListRatingParam struct {
@@ -33,6 +66,37 @@ type ListRatingParam struct {
 	Limit  int32
 }
 
+type StationSummary struct {
+	StationID     int64   `json:"station_id" db:"station_id"`
+	RatingCount   int64   `json:"rating_count" db:"rating_count"`
+	AverageRating float64 `json:"average_rating" db:"average_rating"`
+	OneStar       int64   `json:"one_star" db:"one_star"`
+	TwoStar       int64   `json:"two_star" db:"two_star"`
+	ThreeStar     int64   `json:"three_star" db:"three_star"`
+	FourStar      int64   `json:"four_star" db:"four_star"`
+	FiveStar      int64   `json:"five_star" db:"five_star"`
+	// Ratings since the start of an open anomaly of the station are left out.
+	Frozen bool `json:"frozen" db:"frozen"`
+	// Average sentiment of scored comments, null when there are none.
+	AverageSentiment *float64 `json:"average_sentiment" db:"average_sentiment"`
+	// Ratings whose stars disagree with sentiment of their comment.
+	SentimentMismatches int64 `json:"sentiment_mismatches" db:"sentiment_mismatches"`
+}
+
+type RatingBatch struct {
+	Ratings    []Rating `json:"ratings"`
+	MissingIDs []int64  `json:"missing_ids"`
+}
+
+type StationSummaryBatch struct {
+	Summaries         []StationSummary `json:"summaries"`
+	MissingStationIDs []int64          `json:"missing_station_ids"`
+}
+
+type BatchStationSummaryParam struct {
+	StationIDs []int64 `json:"station_ids"`
+}
+
 // HTTPError types
 
 type Empty struct {
@@ -42,10 +106,34 @@ type HTTPError400 struct {
 	Message []Empty `json:"message" example:[Empty]`
 }
 
+type HTTPError401 struct {
+	Message string `json:"message" example:"authentication required"`
+}
+
+type HTTPError403 struct {
+	Message string `json:"message" example:"missing scope ratings:write"`
+}
+
 type HTTPError404 struct {
 	Message Empty `json:"message" example:Empty`
 }
 
+type HTTPError409 struct {
+	Message string `json:"message" example:"request with this idempotency key is still in progress"`
+}
+
+type HTTPError422 struct {
+	Message string `json:"message" example:"idempotency key was already used with a different request"`
+}
+
+type HTTPError412 struct {
+	Message string `json:"message" example:"rating was modified by another request"`
+}
+
+type HTTPError428 struct {
+	Message string `json:"message" example:"If-Match header is required"`
+}
+
 type HTTPError500 struct {
 	Message Empty `json:"message" example:Empty`
 }
@@ -58,26 +146,32 @@ type HTTPError500 struct {
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "Rating ID"
+// @Param        If-None-Match  header  string  false  "ETag of cached rating"
 // @Success      200  {object}  Rating
+// @Success      304
 // @Failure      404  {object}  HTTPError404
 // @Failure      500  {object}  HTTPError500
 // @Router       /ratings/{id} [get]
 func (store *Store) GetByID(ctx context.Context, id int64) (rating Rating, err error) {
-	const query = `SELECT * FROM "ratings" WHERE "rating_id" = $1`
-	err = store.db.GetContext(ctx, &rating, query, id)
+	ctx, end := observe(ctx, "GetByID")
+	defer end()
+
+	const query = `SELECT ` + ratingColumns + ` FROM "ratings" WHERE "rating_id" = $1`
+	err = store.readGet(ctx, &rating, query, id)
 
 	return
 }
 
 /// GetAll godoc
 // @Summary      Get all ratings and comments
-// @Description  get all ratings
+// @Description  get all ratings, or a batch of ratings by their IDs when ids parameter is set (returns RatingBatch)
 // @ID           get-all-ratings
 // @Tags         ratings
 // @Accept 		 mpfd
 // @Produce      json
-// @Param        offset   query      int  true  "Offset"
-// @Param        limit   query      int  true  "Limit"
+// @Param        offset   query      int  false  "Offset"
+// @Param        limit   query      int  false  "Limit"
+// @Param        ids   query      string  false  "Comma separated list of rating IDs"
 // @Success      200  {object}  []Rating
 // @Header		 200 {object}	BasicHeader
 // @Failure      400  {object}  HTTPError400
@@ -85,9 +179,12 @@ func (store *Store) GetByID(ctx context.Context, id int64) (rating Rating, err e
 // @Failure      500  {object}  HTTPError500
 // @Router       /ratings [get]
 func (store *Store) GetAll(ctx context.Context, arg ListRatingParam) (ratings []Rating, err error) {
-	const query = `SELECT * FROM "ratings" OFFSET $1 LIMIT $2`
+	ctx, end := observe(ctx, "GetAll")
+	defer end()
+
+	const query = `SELECT ` + ratingColumns + ` FROM "ratings" WHERE "status" = 'published' OFFSET $1 LIMIT $2`
 	ratings = []Rating{}
-	err = store.db.SelectContext(ctx, &ratings, query, arg.Offset, arg.Limit)
+	err = store.readSelect(ctx, &ratings, query, arg.Offset, arg.Limit)
 
 	return
 }
@@ -99,93 +196,320 @@ func (store *Store) GetAll(ctx context.Context, arg ListRatingParam) (ratings []
 // @Tags         ratings
 // @Accept       json
 // @Produce      json
+// @Param        Idempotency-Key  header  string  false  "Key that makes retries of the request safe"
 // @Param        message  body  CreateRatingParam  true  "Rating parametres"
 // @Success      201  {object}  Rating
 // @Failure      404  {object}  HTTPError404
+// @Failure      409  {object}  HTTPError409
+// @Failure      422  {object}  HTTPError422
 // @Failure      500  {object}  HTTPError500
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Router       /ratings [post]
-func (store *Store) Create(ctx context.Context, arg CreateRatingParam) (Rating, error) {
+func (store *Store) Create(ctx context.Context, arg CreateRatingParam) (rating Rating, err error) {
+	ctx, end := observe(ctx, "Create")
+	defer end()
+
+	// Signature of the comment is stored with the rating, when there is one.
 	const query = `
-	INSERT INTO "ratings"("station_id", "user_id", "rating", "comment") 
-	VALUES ($1, $2, $3, $4)
-	RETURNING "rating_id", "station_id", "user_id", "rating", "comment", "created_at"
-	`
-	row := store.db.QueryRowContext(ctx, query, arg.Station_id, arg.User_id, arg.Rating, arg.Comment)
+	WITH "inserted" AS (
+		INSERT INTO "ratings"("station_id", "user_id", "rating", "comment", "status", "moderation_reasons", "sentiment") 
+		VALUES ($1, $2, $3, $4, $5, $6, $9)
+		RETURNING ` + ratingColumns + `
+	), "signature" AS (
+		INSERT INTO "comment_signatures"("rating_id", "user_id", "signature", "bands")
+		SELECT "rating_id", "user_id", $7::BIGINT[], $8::BIGINT[] FROM "inserted"
+		WHERE $7::BIGINT[] IS NOT NULL
+	)
+	SELECT * FROM "inserted"`
 
-	var rating Rating
+	// Check comment before it is stored.
+	comment, err := store.moderate(arg.Comment)
+	if err != nil {
+		return
+	}
+	signature, err := store.checkDuplicates(ctx, store.db, 0, arg.User_id, &comment, nil)
+	if err != nil {
+		return
+	}
+	signatureValues, bands := signatureParams(signature)
 
-	err := row.Scan(
-		&rating.ID,
-		&rating.Station_id,
-		&rating.User_id,
-		&rating.Rating,
-		&rating.Comment,
-		&rating.CreatedAt,
-	)
+	err = store.db.GetContext(ctx, &rating, query, arg.Station_id, arg.User_id, arg.Rating, comment.Comment, comment.Status, comment.Reasons, signatureValues, bands, commentSentiment(comment.Comment))
+	if err == nil {
+		metrics.RatingsCreated.WithLabelValues(strconv.FormatInt(rating.Rating, 10)).Inc()
+		store.notifyWrite(ctx, rating.Station_id)
+	}
 
-	return rating, err
+	return
 }
 
 /// Update godoc
 // @Summary      Update a rating
-// @Description  update rating
+// @Description  update rating, If-Match header must contain current ETag of the rating
 // @ID           update-rating
 // @Tags         ratings
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "Rating ID"
+// @Param        If-Match  header  string  true  "ETag of the rating"
 // @Param        message  body  UpdateRatingParam  true  "Rating parametres"
 // @Success      201  {object}  Rating
 // @Failure 	 400  {object}  HTTPError400
 // @Failure      404  {object}  HTTPError404
+// @Failure      412  {object}  HTTPError412
+// @Failure      428  {object}  HTTPError428
 // @Failure      500  {object}  HTTPError500
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Router       /ratings/{id} [put]
-func (store *Store) Update(ctx context.Context, arg UpdateRatingParam, id int64) (Rating, error) {
+func (store *Store) Update(ctx context.Context, arg UpdateRatingParam, id int64) (rating Rating, err error) {
+	ctx, end := observe(ctx, "Update")
+	defer end()
+
+	// Signature of the comment replaces the stored one, which is removed when the
+	// comment has none.
 	const query = `
-	UPDATE "ratings"
-	SET "station_id" = $2,
-		"user_id" = $3,
-		"rating" = $4,
-		"comment" = $5
-	WHERE "rating_id" = $1
-	RETURNING "rating_id", "station_id", "user_id", "rating", "comment", "created_at"
-	`
-	row := store.db.QueryRowContext(ctx, query, id, arg.Station_id, arg.User_id, arg.Rating, arg.Comment)
+	WITH "old" AS (
+		SELECT "station_id" FROM "ratings" WHERE "rating_id" = $1
+	), "updated" AS (
+		UPDATE "ratings"
+		SET "station_id" = $2,
+			"user_id" = $3,
+			"rating" = $4,
+			"comment" = $5,
+			"status" = CASE WHEN "status" = 'published' THEN $7 ELSE "status" END,
+			"moderation_reasons" = CASE WHEN "status" = 'published' THEN $8::JSONB ELSE "moderation_reasons" || $8::JSONB END,
+			"sentiment" = $9,
+			"version" = "version" + 1
+		WHERE "rating_id" = $1 AND "version" = $6
+		RETURNING ` + ratingColumns + `, (SELECT "station_id" FROM "old") AS "old_station_id"
+	), "signature" AS (
+		INSERT INTO "comment_signatures"("rating_id", "user_id", "signature", "bands")
+		SELECT "rating_id", "user_id", $10::BIGINT[], $11::BIGINT[] FROM "updated"
+		WHERE $10::BIGINT[] IS NOT NULL
+		ON CONFLICT ("rating_id") DO UPDATE
+		SET "user_id" = EXCLUDED."user_id", "signature" = EXCLUDED."signature", "bands" = EXCLUDED."bands", "created_at" = now()
+	), "unsigned" AS (
+		DELETE FROM "comment_signatures"
+		WHERE "rating_id" IN (SELECT "rating_id" FROM "updated") AND $10::BIGINT[] IS NULL
+	)
+	SELECT * FROM "updated"`
 
-	var rating Rating
+	// Check comment before it is stored, like when a rating is created. Edits only
+	// hold published ratings for moderation, pending and removed ratings keep their
+	// status.
+	comment, err := store.moderate(arg.Comment)
+	if err != nil {
+		return
+	}
+	signature, err := store.checkDuplicates(ctx, store.db, id, arg.User_id, &comment, nil)
+	if err != nil {
+		return
+	}
+	signatureValues, bands := signatureParams(signature)
+
+	var result updatedRating
+	err = store.db.GetContext(ctx, &result, query, id, arg.Station_id, arg.User_id, arg.Rating, comment.Comment, arg.Version, comment.Status, comment.Reasons, commentSentiment(comment.Comment), signatureValues, bands)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = store.versionMismatch(ctx, id)
+	}
+	if err != nil {
+		return
+	}
+
+	store.notifyWrite(ctx, result.OldStationID, result.Station_id)
+	return result.Rating, nil
+}
 
-	err := row.Scan(
-		&rating.ID,
-		&rating.Station_id,
-		&rating.User_id,
-		&rating.Rating,
-		&rating.Comment,
-		&rating.CreatedAt,
+/// Patch godoc
+// @Summary      Partially update a rating
+// @Description  update only given fields of rating, If-Match header must contain current ETag of the rating
+// @ID           patch-rating
+// @Tags         ratings
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Rating ID"
+// @Param        If-Match  header  string  true  "ETag of the rating"
+// @Param        message  body  PatchRatingParam  true  "Rating parametres"
+// @Success      200  {object}  Rating
+// @Failure 	 400  {object}  HTTPError400
+// @Failure      404  {object}  HTTPError404
+// @Failure      412  {object}  HTTPError412
+// @Failure      428  {object}  HTTPError428
+// @Failure      500  {object}  HTTPError500
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
+// @Router       /ratings/{id} [patch]
+func (store *Store) Patch(ctx context.Context, arg PatchRatingParam, id int64) (rating Rating, err error) {
+	ctx, end := observe(ctx, "Patch")
+	defer end()
+
+	// Signature is replaced or removed like in Update when the comment changes,
+	// otherwise only its user follows the rating.
+	const query = `
+	WITH "old" AS (
+		SELECT "station_id" FROM "ratings" WHERE "rating_id" = $1
+	), "updated" AS (
+		UPDATE "ratings"
+		SET "station_id" = COALESCE($2, "station_id"),
+			"user_id" = COALESCE($3, "user_id"),
+			"rating" = COALESCE($4, "rating"),
+			"comment" = COALESCE($5, "comment"),
+			"status" = CASE WHEN $7::TEXT IS NOT NULL AND "status" = 'published' THEN $7 ELSE "status" END,
+			"moderation_reasons" = CASE
+				WHEN $8::JSONB IS NULL THEN "moderation_reasons"
+				WHEN "status" = 'published' THEN $8::JSONB
+				ELSE "moderation_reasons" || $8::JSONB
+			END,
+			"sentiment" = CASE WHEN $5::TEXT IS NULL THEN "sentiment" ELSE $9 END,
+			"version" = "version" + 1
+		WHERE "rating_id" = $1 AND "version" = $6
+		RETURNING ` + ratingColumns + `, (SELECT "station_id" FROM "old") AS "old_station_id"
+	), "signature" AS (
+		INSERT INTO "comment_signatures"("rating_id", "user_id", "signature", "bands")
+		SELECT "rating_id", "user_id", $10::BIGINT[], $11::BIGINT[] FROM "updated"
+		WHERE $10::BIGINT[] IS NOT NULL
+		ON CONFLICT ("rating_id") DO UPDATE
+		SET "user_id" = EXCLUDED."user_id", "signature" = EXCLUDED."signature", "bands" = EXCLUDED."bands", "created_at" = now()
+	), "unsigned" AS (
+		DELETE FROM "comment_signatures"
+		WHERE "rating_id" IN (SELECT "rating_id" FROM "updated") AND $5::TEXT IS NOT NULL AND $10::BIGINT[] IS NULL
+	), "owner" AS (
+		UPDATE "comment_signatures" SET "user_id" = "updated"."user_id"
+		FROM "updated"
+		WHERE "comment_signatures"."rating_id" = "updated"."rating_id" AND $5::TEXT IS NULL
 	)
+	SELECT * FROM "updated"`
+
+	// Check changed comment before it is stored, otherwise its status is kept.
+	// Like in Update, only published ratings can be held for moderation.
+	var comment, status, reasons, sentiment interface{}
+	var signatureValues, bands pq.Int64Array
+	if arg.Comment != nil {
+		moderated, err := store.moderate(*arg.Comment)
+		if err != nil {
+			return rating, err
+		}
+
+		// Comment is compared with comments of the user the rating will have.
+		var userID int64
+		if arg.User_id != nil {
+			userID = *arg.User_id
+		} else {
+			const userQuery = `SELECT "user_id" FROM "ratings" WHERE "rating_id" = $1 AND "version" = $2`
+			err := store.db.GetContext(ctx, &userID, userQuery, id, arg.Version)
+			if errors.Is(err, sql.ErrNoRows) {
+				err = store.versionMismatch(ctx, id)
+			}
+			if err != nil {
+				return rating, err
+			}
+		}
+
+		signature, err := store.checkDuplicates(ctx, store.db, id, userID, &moderated, nil)
+		if err != nil {
+			return rating, err
+		}
+		signatureValues, bands = signatureParams(signature)
 
-	return rating, err
+		comment, status, reasons = moderated.Comment, moderated.Status, moderated.Reasons
+		sentiment = commentSentiment(moderated.Comment)
+	}
+
+	var result updatedRating
+	err = store.db.GetContext(ctx, &result, query, id, arg.Station_id, arg.User_id, arg.Rating, comment, arg.Version, status, reasons, sentiment, signatureValues, bands)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = store.versionMismatch(ctx, id)
+	}
+	if err != nil {
+		return
+	}
+
+	store.notifyWrite(ctx, result.OldStationID, result.Station_id)
+	return result.Rating, nil
 }
 
 /// Delete godoc
 // @Summary      Delete a rating
-// @Description  delete rating
+// @Description  delete rating, If-Match header must contain current ETag of the rating
 // @ID           delete-rating
 // @Tags         ratings
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "Rating ID"
+// @Param        If-Match  header  string  true  "ETag of the rating"
 // @Success      204
 // @Failure      404  {object}  HTTPError404
+// @Failure      412  {object}  HTTPError412
+// @Failure      428  {object}  HTTPError428
 // @Failure      500  {object}  HTTPError500
+// @Security     ApiKeyAuth
+// @Security     BearerAuth
 // @Router       /ratings/{id} [delete]
+func (store *Store) DeleteVersion(ctx context.Context, id, version int64) error {
+	ctx, end := observe(ctx, "DeleteVersion")
+	defer end()
+
+	const query = `
+	DELETE FROM ratings
+	WHERE "rating_id" = $1 AND "version" = $2
+	RETURNING "station_id"
+	`
+	var stationID int64
+	err := store.db.GetContext(ctx, &stationID, query, id, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return store.versionMismatch(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	metrics.RatingsDeleted.Inc()
+	store.notifyWrite(ctx, stationID)
+	return nil
+}
+
+// Deletes a rating regardless of its version.
 func (store *Store) Delete(ctx context.Context, id int64) error {
+	ctx, end := observe(ctx, "Delete")
+	defer end()
+
 	const query = `
 	DELETE FROM ratings
 	WHERE "rating_id" = $1
+	RETURNING "station_id"
 	`
-	_, err := store.db.ExecContext(ctx, query, id)
+	var stationID int64
+	err := store.db.GetContext(ctx, &stationID, query, id)
+
+	// Deleting a missing rating is not an error.
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
 
-	return err
+	metrics.RatingsDeleted.Inc()
+	store.notifyWrite(ctx, stationID)
+	return nil
+}
+
+// Explains why a conditional write didn't affect any row. Returns ErrVersionMismatch
+// when the rating exists, otherwise sql.ErrNoRows.
+func (store *Store) versionMismatch(ctx context.Context, id int64) error {
+	const query = `SELECT EXISTS(SELECT 1 FROM "ratings" WHERE "rating_id" = $1)`
+
+	var exists bool
+	if err := store.db.GetContext(ctx, &exists, query, id); err != nil {
+		return err
+	}
+
+	if exists {
+		return ErrVersionMismatch
+	}
+
+	return sql.ErrNoRows
 }
 
 /// GetAllByStation godoc
@@ -202,9 +526,137 @@ func (store *Store) Delete(ctx context.Context, id int64) error {
 // @Failure      500  {object}  HTTPError500
 // @Router       /ratings/station/{id} [get]
 func (store *Store) GetAllByStation(ctx context.Context, stationID int64) (ratings []Rating, err error) {
-	const query = `SELECT * FROM "ratings" WHERE "station_id" = $1`
+	ctx, end := observe(ctx, "GetAllByStation")
+	defer end()
+
+	const query = `SELECT ` + ratingColumns + ` FROM "ratings" WHERE "station_id" = $1 AND "status" = 'published'`
 	ratings = []Rating{}
-	err = store.db.SelectContext(ctx, &ratings, query, stationID)
+	err = store.readSelect(ctx, &ratings, query, stationID)
 
 	return
 }
+
+/// GetStationSummary godoc
+// @Summary      Get rating summary of a single station by its ID
+// @Description  get rating summary by station
+// @ID           get-rating-summary-by-station
+// @Tags         ratings
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "ID of station"
+// @Success      200  {object}  StationSummary
+// @Failure      400  {object}  HTTPError400
+// @Failure      500  {object}  HTTPError500
+// @Router       /ratings/station/{id}/summary [get]
+func (store *Store) GetStationSummary(ctx context.Context, stationID int64) (summary StationSummary, err error) {
+	ctx, end := observe(ctx, "GetStationSummary")
+	defer end()
+
+	const query = `
+	WITH "frozen" AS (
+		SELECT MIN("window_start") AS "since" FROM "anomalies"
+		WHERE "station_id" = $1 AND "frozen" AND "resolved_at" IS NULL
+	)
+	SELECT $1::BIGINT AS "station_id",
+		COUNT(*) AS "rating_count",
+		COALESCE(AVG("rating"), 0) AS "average_rating",
+		COUNT(*) FILTER (WHERE "rating" = 1) AS "one_star",
+		COUNT(*) FILTER (WHERE "rating" = 2) AS "two_star",
+		COUNT(*) FILTER (WHERE "rating" = 3) AS "three_star",
+		COUNT(*) FILTER (WHERE "rating" = 4) AS "four_star",
+		COUNT(*) FILTER (WHERE "rating" = 5) AS "five_star",
+		(SELECT "since" IS NOT NULL FROM "frozen") AS "frozen",
+		AVG("sentiment") AS "average_sentiment",
+		COUNT(*) FILTER (WHERE ` + sentimentMismatch + `) AS "sentiment_mismatches"
+	FROM "ratings"
+	WHERE "station_id" = $1 AND "status" = 'published'
+		AND "created_at" < COALESCE((SELECT "since" FROM "frozen"), 'infinity')
+	`
+	err = store.readGet(ctx, &summary, query, stationID)
+
+	return
+}
+
+// Returns ratings with given IDs in a single query and reports IDs that were not found.
+func (store *Store) GetByIDs(ctx context.Context, ids []int64) (batch RatingBatch, err error) {
+	ctx, end := observe(ctx, "GetByIDs")
+	defer end()
+
+	const query = `SELECT ` + ratingColumns + ` FROM "ratings" WHERE "rating_id" = ANY($1) AND "status" = 'published' ORDER BY "rating_id"`
+	batch.Ratings = []Rating{}
+	if err = store.readSelect(ctx, &batch.Ratings, query, pq.Array(ids)); err != nil {
+		return
+	}
+
+	found := make(map[int64]bool, len(batch.Ratings))
+	for _, rating := range batch.Ratings {
+		found[rating.ID] = true
+	}
+	batch.MissingIDs = missingIDs(ids, found)
+
+	return
+}
+
+/// GetStationSummaries godoc
+// @Summary      Get rating summaries of multiple stations
+// @Description  get rating summaries of a batch of stations, stations without ratings are reported as missing
+// @ID           batch-rating-summary-by-station
+// @Tags         ratings
+// @Accept       json
+// @Produce      json
+// @Param        message  body  BatchStationSummaryParam  true  "Station IDs"
+// @Success      200  {object}  StationSummaryBatch
+// @Failure      400  {object}  HTTPError400
+// @Failure      500  {object}  HTTPError500
+// @Router       /stations/ratings:batchSummary [post]
+func (store *Store) GetStationSummaries(ctx context.Context, stationIDs []int64) (batch StationSummaryBatch, err error) {
+	ctx, end := observe(ctx, "GetStationSummaries")
+	defer end()
+
+	const query = `
+	SELECT "ratings"."station_id",
+		COUNT(*) AS "rating_count",
+		COALESCE(AVG("rating"), 0) AS "average_rating",
+		COUNT(*) FILTER (WHERE "rating" = 1) AS "one_star",
+		COUNT(*) FILTER (WHERE "rating" = 2) AS "two_star",
+		COUNT(*) FILTER (WHERE "rating" = 3) AS "three_star",
+		COUNT(*) FILTER (WHERE "rating" = 4) AS "four_star",
+		COUNT(*) FILTER (WHERE "rating" = 5) AS "five_star",
+		"frozen"."since" IS NOT NULL AS "frozen",
+		AVG("sentiment") AS "average_sentiment",
+		COUNT(*) FILTER (WHERE ` + sentimentMismatch + `) AS "sentiment_mismatches"
+	FROM "ratings"
+	LEFT JOIN (
+		SELECT "station_id", MIN("window_start") AS "since" FROM "anomalies"
+		WHERE "frozen" AND "resolved_at" IS NULL
+		GROUP BY "station_id"
+	) AS "frozen" ON "frozen"."station_id" = "ratings"."station_id"
+	WHERE "ratings"."station_id" = ANY($1) AND "status" = 'published'
+		AND "created_at" < COALESCE("frozen"."since", 'infinity')
+	GROUP BY "ratings"."station_id", "frozen"."since"
+	ORDER BY "ratings"."station_id"
+	`
+	batch.Summaries = []StationSummary{}
+	if err = store.readSelect(ctx, &batch.Summaries, query, pq.Array(stationIDs)); err != nil {
+		return
+	}
+
+	found := make(map[int64]bool, len(batch.Summaries))
+	for _, summary := range batch.Summaries {
+		found[summary.StationID] = true
+	}
+	batch.MissingStationIDs = missingIDs(stationIDs, found)
+
+	return
+}
+
+func missingIDs(ids []int64, found map[int64]bool) []int64 {
+	missing := []int64{}
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+
+	return missing
+}
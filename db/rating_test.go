@@ -10,11 +10,12 @@ import (
 )
 
 func createRandomRating(t *testing.T) Rating {
+	stars := util.RandomStars()
 	arg := CreateRatingParam{
 		Station_id: util.RandomInt(1261, 654561),
 		User_id:    util.RandomInt(1261, 654561),
-		Rating:     util.RandomInt(1, 5),
-		Comment:    util.RandomString(5),
+		Rating:     stars,
+		Comment:    util.RandomComment(stars),
 	}
 
 	result, err := testStore.Create(context.Background(), arg)
@@ -30,6 +31,7 @@ func createRandomRating(t *testing.T) Rating {
 
 	require.NotZero(t, result.ID)
 	require.NotZero(t, result.CreatedAt)
+	require.Equal(t, int64(1), result.Version)
 
 	return result
 }
@@ -85,6 +87,7 @@ func TestUpdateRating(t *testing.T) {
 		User_id:    util.RandomInt(1261, 654561),
 		Rating:     util.RandomInt(1, 5),
 		Comment:    util.RandomString(5),
+		Version:    rating1.Version,
 	}
 
 	rating2, err := testStore.Update(context.Background(), arg, rating1.ID)
@@ -98,7 +101,37 @@ func TestUpdateRating(t *testing.T) {
 	require.Equal(t, arg.Rating, rating2.Rating)
 	require.Equal(t, arg.Comment, rating2.Comment)
 	require.Equal(t, rating1.CreatedAt, rating2.CreatedAt)
+	require.Equal(t, rating1.Version+1, rating2.Version)
 
+	// Update based on the old version is rejected.
+	_, err = testStore.Update(context.Background(), arg, rating1.ID)
+	require.ErrorIs(t, err, ErrVersionMismatch)
+
+	_, err = testStore.Update(context.Background(), arg, 0)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestPatchRating(t *testing.T) {
+	rating1 := createRandomRating(t)
+
+	comment := util.RandomString(8)
+	arg := PatchRatingParam{
+		Comment: &comment,
+		Version: rating1.Version,
+	}
+
+	rating2, err := testStore.Patch(context.Background(), arg, rating1.ID)
+	require.NoError(t, err)
+
+	require.Equal(t, rating1.ID, rating2.ID)
+	require.Equal(t, rating1.Station_id, rating2.Station_id)
+	require.Equal(t, rating1.User_id, rating2.User_id)
+	require.Equal(t, rating1.Rating, rating2.Rating)
+	require.Equal(t, comment, rating2.Comment)
+	require.Equal(t, rating1.Version+1, rating2.Version)
+
+	_, err = testStore.Patch(context.Background(), arg, rating1.ID)
+	require.ErrorIs(t, err, ErrVersionMismatch)
 }
 
 func TestDeleteRating(t *testing.T) {
@@ -110,3 +143,78 @@ func TestDeleteRating(t *testing.T) {
 	require.EqualError(t, err, sql.ErrNoRows.Error())
 	require.Empty(t, rating2)
 }
+
+func TestDeleteRatingVersion(t *testing.T) {
+	rating1 := createRandomRating(t)
+
+	err := testStore.DeleteVersion(context.Background(), rating1.ID, rating1.Version+1)
+	require.ErrorIs(t, err, ErrVersionMismatch)
+
+	err = testStore.DeleteVersion(context.Background(), rating1.ID, rating1.Version)
+	require.NoError(t, err)
+
+	err = testStore.DeleteVersion(context.Background(), rating1.ID, rating1.Version)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestGetStationSummary(t *testing.T) {
+	rating1 := createRandomRating(t)
+
+	arg := CreateRatingParam{
+		Station_id: rating1.Station_id,
+		User_id:    util.RandomInt(1261, 654561),
+		Rating:     util.RandomInt(1, 5),
+		Comment:    util.RandomString(5),
+	}
+	_, err := testStore.Create(context.Background(), arg)
+	require.NoError(t, err)
+
+	ratings, err := testStore.GetAllByStation(context.Background(), rating1.Station_id)
+	require.NoError(t, err)
+
+	summary, err := testStore.GetStationSummary(context.Background(), rating1.Station_id)
+	require.NoError(t, err)
+
+	require.Equal(t, rating1.Station_id, summary.StationID)
+	require.Equal(t, int64(len(ratings)), summary.RatingCount)
+	require.Equal(t, summary.RatingCount, summary.OneStar+summary.TwoStar+summary.ThreeStar+summary.FourStar+summary.FiveStar)
+	require.GreaterOrEqual(t, summary.AverageRating, 1.0)
+	require.LessOrEqual(t, summary.AverageRating, 5.0)
+}
+
+func TestGetRatingsByIDs(t *testing.T) {
+	rating1 := createRandomRating(t)
+	rating2 := createRandomRating(t)
+
+	// Delete a rating, so that its ID is reported as missing.
+	rating3 := createRandomRating(t)
+	require.NoError(t, testStore.Delete(context.Background(), rating3.ID))
+
+	batch, err := testStore.GetByIDs(context.Background(), []int64{rating1.ID, rating2.ID, rating3.ID})
+	require.NoError(t, err)
+
+	require.Len(t, batch.Ratings, 2)
+	require.Equal(t, rating1.ID, batch.Ratings[0].ID)
+	require.Equal(t, rating2.ID, batch.Ratings[1].ID)
+	require.Equal(t, []int64{rating3.ID}, batch.MissingIDs)
+}
+
+func TestGetStationSummaries(t *testing.T) {
+	rating1 := createRandomRating(t)
+	rating2 := createRandomRating(t)
+	require.NoError(t, testStore.Delete(context.Background(), rating2.ID))
+
+	batch, err := testStore.GetStationSummaries(context.Background(), []int64{rating1.Station_id, rating2.Station_id})
+	require.NoError(t, err)
+
+	require.Len(t, batch.Summaries, 1)
+	require.Equal(t, rating1.Station_id, batch.Summaries[0].StationID)
+	require.NotZero(t, batch.Summaries[0].RatingCount)
+
+	// Other ratings with the same random station ID may exist.
+	summary, err := testStore.GetStationSummary(context.Background(), rating2.Station_id)
+	require.NoError(t, err)
+	if summary.RatingCount == 0 {
+		require.Equal(t, []int64{rating2.Station_id}, batch.MissingStationIDs)
+	}
+}
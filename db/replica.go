@@ -0,0 +1,217 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"rating-service/config"
+	"reflect"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Read-only copy of the database that serves reads while it is healthy.
+type replica struct {
+	name    string
+	db      *sqlx.DB
+	healthy int32
+}
+
+func (r *replica) isHealthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+// Marks replica as healthy or not and reports whether its state changed.
+func (r *replica) setHealthy(healthy bool) bool {
+	var value int32
+	if healthy {
+		value = 1
+	}
+
+	return atomic.SwapInt32(&r.healthy, value) != value
+}
+
+type primaryKey struct{}
+
+// Returns context whose reads go to primary database, e.g. to read a rating
+// that is about to be changed without replication lag.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// Connects to primary database and read replicas with pool settings from
+// configuration. Replicas that are down are skipped until they are up again.
+func Open(config config.Config) (*Store, error) {
+	store, err := Connect(config.DBDriver, config.DBSource)
+	if err != nil {
+		return nil, err
+	}
+	configurePool(store.db, config)
+
+	for i, source := range config.DBReplicaSources {
+		// Replicas are connected lazily, so service starts while they are down.
+		db, err := sqlx.Open(config.DBDriver, source)
+		if err != nil {
+			store.Close()
+			return nil, fmt.Errorf("failed to open replica %d: %w", i+1, err)
+		}
+		configurePool(db, config)
+
+		// Replicas start healthy, so the first check logs those that are down.
+		store.replicas = append(store.replicas, &replica{
+			name:    fmt.Sprintf("replica_%d", i+1),
+			db:      db,
+			healthy: 1,
+		})
+	}
+
+	if len(store.replicas) > 0 {
+		store.checkReplicas(config.DBReplicaCheckInterval)
+		go store.monitorReplicas(config.DBReplicaCheckInterval)
+	}
+
+	return store, nil
+}
+
+func configurePool(db *sqlx.DB, config config.Config) {
+	db.SetMaxOpenConns(config.DBMaxOpenConns)
+	db.SetMaxIdleConns(config.DBMaxIdleConns)
+	db.SetConnMaxLifetime(config.DBConnMaxLifetime)
+	db.SetConnMaxIdleTime(config.DBConnMaxIdleTime)
+}
+
+// Stops replica checks and closes all connections.
+func (store *Store) Close() error {
+	store.closeOnce.Do(func() { close(store.stop) })
+
+	for _, r := range store.replicas {
+		r.db.Close()
+	}
+
+	return store.db.Close()
+}
+
+// Returns database for read queries and replica it belongs to. Replicas are used
+// in turns, and primary is used when none of them is healthy or context requires it.
+func (store *Store) reader(ctx context.Context) (*sqlx.DB, *replica) {
+	if len(store.replicas) == 0 || ctx.Value(primaryKey{}) != nil {
+		return store.db, nil
+	}
+
+	start := int(atomic.AddUint32(&store.next, 1))
+	for i := range store.replicas {
+		r := store.replicas[(start+i)%len(store.replicas)]
+		if r.isHealthy() {
+			trace.SpanFromContext(ctx).SetAttributes(attribute.String("db.instance", r.name))
+			return r.db, r
+		}
+	}
+
+	return store.db, nil
+}
+
+// Runs read query on a replica. When the replica can't be reached it is marked
+// unhealthy, until the next check finds it up, and the query is retried on primary.
+func (store *Store) read(ctx context.Context, query func(db *sqlx.DB) error) error {
+	db, r := store.reader(ctx)
+	err := query(db)
+	if r == nil || ctx.Err() != nil || !isConnectionError(err) {
+		return err
+	}
+
+	if r.setHealthy(false) {
+		zap.L().Warn("Read replica is down, its reads go to other replicas or primary", zap.String("replica", r.name), zap.Error(err))
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("db.instance", "primary"))
+
+	return query(store.db)
+}
+
+// Reads rows into slice dest, see read.
+func (store *Store) readSelect(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return store.read(ctx, func(db *sqlx.DB) error {
+		// Rows of a failed attempt are dropped.
+		slice := reflect.ValueOf(dest).Elem()
+		slice.Set(reflect.MakeSlice(slice.Type(), 0, 0))
+
+		return db.SelectContext(ctx, dest, query, args...)
+	})
+}
+
+// Reads a single row into dest, see read.
+func (store *Store) readGet(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return store.read(ctx, func(db *sqlx.DB) error {
+		return db.GetContext(ctx, dest, query, args...)
+	})
+}
+
+// Reports whether err means that database could not be reached, as opposed to an
+// error of the query itself.
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// Connection exceptions and shutdowns, e.g. when replica is restarted.
+		return pqErr.Code.Class() == "08" || pqErr.Code == "57P01" || pqErr.Code == "57P02" || pqErr.Code == "57P03"
+	}
+
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.As(err, &netErr)
+}
+
+// Returns health of each replica by its name.
+func (store *Store) ReplicaHealth() map[string]bool {
+	result := make(map[string]bool, len(store.replicas))
+	for _, r := range store.replicas {
+		result[r.name] = r.isHealthy()
+	}
+
+	return result
+}
+
+func (store *Store) monitorReplicas(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-store.stop:
+			return
+		case <-ticker.C:
+			store.checkReplicas(interval)
+		}
+	}
+}
+
+// Pings every replica and logs replicas that went down or came back.
+func (store *Store) checkReplicas(timeout time.Duration) {
+	for _, r := range store.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := r.db.PingContext(ctx)
+		cancel()
+
+		if !r.setHealthy(err == nil) {
+			continue
+		}
+
+		if err != nil {
+			zap.L().Warn("Read replica is down, its reads go to other replicas or primary", zap.String("replica", r.name), zap.Error(err))
+		} else {
+			zap.L().Info("Read replica is up", zap.String("replica", r.name))
+		}
+	}
+}
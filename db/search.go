@@ -0,0 +1,82 @@
+package db
+
+import "context"
+
+// Text search configs by language of search query.
+var searchConfigs = map[string]string{
+	"simple":    "simple",
+	"english":   "english",
+	"slovenian": "slovenian_unaccent",
+}
+
+// Comment with HTML special characters escaped, so that highlight only contains
+// markup added by the search.
+const escapedComment = `replace(replace(replace(replace("comment", '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;')`
+
+type SearchRatingParam struct {
+	Query    string
+	Language string
+	Filter   RatingFilter
+	Offset   int32
+	Limit    int32
+}
+
+// Rating that matched a search query. Highlight is HTML escaped comment with
+// matches wrapped in <mark> tags.
+type SearchResult struct {
+	Rating
+	Rank      float64 `json:"rank" db:"rank"`
+	Highlight string  `json:"highlight" db:"highlight"`
+}
+
+/// Search godoc
+// @Summary      Search comments of ratings
+// @Description  full text search of comments, results are ordered by relevance and matches are highlighted with <mark> tags in HTML escaped comment
+// @ID           search-ratings
+// @Tags         ratings
+// @Accept       json
+// @Produce      json
+// @Param        q   query      string  true  "Search query, supports quoted phrases, OR and -word"
+// @Param        language   query      string  false  "Language of query (simple, english or slovenian), simple by default"
+// @Param        station_id   query      int  false  "ID of station"
+// @Param        from   query      string  false  "Include ratings created at or after (RFC 3339)"
+// @Param        to   query      string  false  "Include ratings created before (RFC 3339)"
+// @Param        offset   query      int  false  "Offset"
+// @Param        limit   query      int  true  "Limit"
+// @Success      200  {object}  []SearchResult
+// @Failure      400  {object}  HTTPError400
+// @Failure      500  {object}  HTTPError500
+// @Router       /ratings/search [get]
+func (store *Store) Search(ctx context.Context, arg SearchRatingParam) (results []SearchResult, err error) {
+	ctx, end := observe(ctx, "Search")
+	defer end()
+
+	const query = `
+	WITH "query" AS (
+		SELECT websearch_to_tsquery($1::regconfig, $2) AS "query"
+	)
+	SELECT ` + ratingColumns + `,
+		ts_rank_cd("search_vector", "query") AS "rank",
+		ts_headline($1::regconfig, ` + escapedComment + `, "query", 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS "highlight"
+	FROM "ratings", "query"
+	WHERE "search_vector" @@ "query"
+		AND "status" = 'published'
+		AND ($3 = 0 OR "station_id" = $3)
+		AND ($4::TIMESTAMP IS NULL OR "created_at" >= $4)
+		AND ($5::TIMESTAMP IS NULL OR "created_at" < $5)
+	ORDER BY "rank" DESC, "created_at" DESC
+	OFFSET $6 LIMIT $7
+	`
+
+	config, ok := searchConfigs[arg.Language]
+	if !ok {
+		config = searchConfigs["simple"]
+	}
+
+	results = []SearchResult{}
+	err = store.readSelect(ctx, &results, query, config, arg.Query,
+		arg.Filter.StationID, nullTime(arg.Filter.From), nullTime(arg.Filter.To), arg.Offset, arg.Limit)
+
+	return
+}
+
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"rating-service/util"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchRatings(t *testing.T) {
+	stationID := util.RandomInt(1261, 654561)
+
+	create := func(comment string) Rating {
+		rating, err := testStore.Create(context.Background(), CreateRatingParam{
+			Station_id: stationID,
+			User_id:    util.RandomInt(1261, 654561),
+			Rating:     2,
+			Comment:    comment,
+		})
+		require.NoError(t, err)
+		return rating
+	}
+
+	cable := create("Broken cable on the charger, again.")
+	blocked := create("Polnilnica je bila spet blokírana, čakal sem pol ure.")
+	create("Vse je delovalo brez težav.")
+
+	search := func(query, language string) []SearchResult {
+		results, err := testStore.Search(context.Background(), SearchRatingParam{
+			Query:    query,
+			Language: language,
+			Filter:   RatingFilter{StationID: stationID},
+			Limit:    10,
+		})
+		require.NoError(t, err)
+		return results
+	}
+
+	// Phrase in English matches inflected words.
+	results := search(`"broken cables"`, "english")
+	require.Len(t, results, 1)
+	require.Equal(t, cable.ID, results[0].ID)
+	require.Positive(t, results[0].Rank)
+	require.Contains(t, results[0].Highlight, "<mark>cable</mark>")
+
+	// Slovenian search ignores accents.
+	results = search("blokirana cakal", "slovenian")
+	require.Len(t, results, 1)
+	require.Equal(t, blocked.ID, results[0].ID)
+	require.Contains(t, results[0].Highlight, "<mark>blokírana</mark>")
+
+	require.Empty(t, search("cable -broken", "simple"))
+
+	// Markup in comments is escaped in highlight.
+	markup := create(`<img src=x onerror="alert(1)"> adapter & charger`)
+	results = search("adapter", "simple")
+	require.Len(t, results, 1)
+	require.Equal(t, markup.ID, results[0].ID)
+	require.Equal(t, `&lt;img src=x onerror=&quot;alert(1)&quot;&gt; <mark>adapter</mark> &amp; charger`, results[0].Highlight)
+
+	// Date filters exclude ratings created before the search window.
+	results, err := testStore.Search(context.Background(), SearchRatingParam{
+		Query:  "cable",
+		Filter: RatingFilter{StationID: stationID, From: time.Now().Add(time.Hour)},
+		Limit:  10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, results)
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"rating-service/sentiment"
+
+	"github.com/lib/pq"
+)
+
+// Condition of ratings whose stars disagree with sentiment of their comment, e.g.
+// 5 stars with "never works".
+const sentimentMismatch = `("rating" >= 4 AND "sentiment" <= -0.3) OR ("rating" <= 2 AND "sentiment" >= 0.3)`
+
+type BackfillSentimentParam struct {
+	// Score all ratings again instead of only ratings without sentiment, e.g.
+	// after the lexicon changed.
+	All       bool
+	BatchSize int
+}
+
+type BackfillSentimentReport struct {
+	Scanned  int64 `json:"scanned"`
+	Changed  int64 `json:"changed"`
+	Stations int   `json:"stations"`
+}
+
+// Returns sentiment of comment, null when it has no known words.
+func commentSentiment(comment string) sql.NullFloat64 {
+	score, ok := sentiment.Score(comment)
+	return sql.NullFloat64{Float64: score, Valid: ok}
+}
+
+// Scores comments of existing ratings in batches. Version of ratings is kept, as
+// sentiment is derived from the comment.
+func (store *Store) BackfillSentiment(ctx context.Context, arg BackfillSentimentParam) (report BackfillSentimentReport, err error) {
+	ctx, end := observe(ctx, "BackfillSentiment")
+	defer end()
+
+	const selectQuery = `
+	SELECT "rating_id", COALESCE("comment", '') AS "comment" FROM "ratings"
+	WHERE "rating_id" > $1 AND ($2 OR "sentiment" IS NULL)
+	ORDER BY "rating_id"
+	LIMIT $3
+	`
+	const updateQuery = `
+	UPDATE "ratings"
+	SET "sentiment" = CASE WHEN "scores"."scored" THEN "scores"."score" END
+	FROM unnest($1::BIGINT[], $2::DOUBLE PRECISION[], $3::BOOLEAN[]) AS "scores"("rating_id", "score", "scored")
+	WHERE "ratings"."rating_id" = "scores"."rating_id"
+		AND "ratings"."sentiment" IS DISTINCT FROM CASE WHEN "scores"."scored" THEN "scores"."score" END
+	RETURNING "ratings"."station_id"
+	`
+
+	changed := make(map[int64]bool)
+	var stationIDs []int64
+	var after int64
+	for {
+		var rows []struct {
+			ID      int64  `db:"rating_id"`
+			Comment string `db:"comment"`
+		}
+		if err = store.db.SelectContext(ctx, &rows, selectQuery, after, arg.All, arg.BatchSize); err != nil {
+			return
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		ids := make(pq.Int64Array, len(rows))
+		scores := make(pq.Float64Array, len(rows))
+		scored := make(pq.BoolArray, len(rows))
+		for i, row := range rows {
+			ids[i] = row.ID
+			scores[i], scored[i] = sentiment.Score(row.Comment)
+		}
+		after = rows[len(rows)-1].ID
+		report.Scanned += int64(len(rows))
+
+		var updated []int64
+		if err = store.db.SelectContext(ctx, &updated, updateQuery, ids, scores, scored); err != nil {
+			return
+		}
+		report.Changed += int64(len(updated))
+
+		for _, stationID := range updated {
+			if !changed[stationID] {
+				changed[stationID] = true
+				stationIDs = append(stationIDs, stationID)
+			}
+		}
+	}
+
+	report.Stations = len(stationIDs)
+	store.notifyWrite(ctx, stationIDs...)
+	return
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"rating-service/util"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStationSentiment(t *testing.T) {
+	ctx := context.Background()
+	stationID := util.RandomInt(1261, 654561)
+
+	// 5 stars with a negative comment disagree.
+	mismatch, err := testStore.Create(ctx, CreateRatingParam{
+		Station_id: stationID,
+		User_id:    util.RandomInt(1261, 654561),
+		Rating:     5,
+		Comment:    "Charger never works.",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, mismatch.Sentiment)
+	require.Less(t, *mismatch.Sentiment, 0.0)
+
+	agreeing, err := testStore.Create(ctx, CreateRatingParam{
+		Station_id: stationID,
+		User_id:    util.RandomInt(1261, 654561),
+		Rating:     5,
+		Comment:    "Odlična polnilnica.",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, agreeing.Sentiment)
+	require.Greater(t, *agreeing.Sentiment, 0.0)
+
+	// Comments without known words are not scored.
+	unscored, err := testStore.Create(ctx, CreateRatingParam{
+		Station_id: stationID,
+		User_id:    util.RandomInt(1261, 654561),
+		Rating:     3,
+	})
+	require.NoError(t, err)
+	require.Nil(t, unscored.Sentiment)
+
+	summary, err := testStore.GetStationSummary(ctx, stationID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), summary.SentimentMismatches)
+	require.NotNil(t, summary.AverageSentiment)
+	require.InDelta(t, (*mismatch.Sentiment+*agreeing.Sentiment)/2, *summary.AverageSentiment, 1e-9)
+
+	batch, err := testStore.GetStationSummaries(ctx, []int64{stationID})
+	require.NoError(t, err)
+	require.Len(t, batch.Summaries, 1)
+	require.Equal(t, summary.SentimentMismatches, batch.Summaries[0].SentimentMismatches)
+}
+
+func TestBackfillSentiment(t *testing.T) {
+	ctx := context.Background()
+	rating, err := testStore.Create(ctx, CreateRatingParam{
+		Station_id: util.RandomInt(1261, 654561),
+		User_id:    util.RandomInt(1261, 654561),
+		Rating:     1,
+		Comment:    "The cable was broken.",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, rating.Sentiment)
+
+	// Ratings stored before sentiment was introduced have none.
+	_, err = testStore.db.ExecContext(ctx, `UPDATE "ratings" SET "sentiment" = NULL WHERE "rating_id" = $1`, rating.ID)
+	require.NoError(t, err)
+
+	// Comment of old ratings can be null.
+	empty := createRandomRating(t)
+	_, err = testStore.db.ExecContext(ctx, `UPDATE "ratings" SET "comment" = NULL, "sentiment" = NULL WHERE "rating_id" = $1`, empty.ID)
+	require.NoError(t, err)
+
+	report, err := testStore.BackfillSentiment(ctx, BackfillSentimentParam{BatchSize: 10})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, report.Changed, int64(1))
+
+	backfilled, err := testStore.GetByID(ctx, rating.ID)
+	require.NoError(t, err)
+	require.Equal(t, rating.Sentiment, backfilled.Sentiment)
+	require.Equal(t, rating.Version, backfilled.Version)
+}
@@ -32,27 +32,40 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/ratings": {
+        "/admin/anomalies": {
             "get": {
-                "description": "get all ratings",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "list windows of unusual ratings of stations, newest first",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
+                    "admin"
                 ],
-                "summary": "Get all ratings and comments",
-                "operationId": "get-all-ratings",
+                "summary": "List anomalies",
+                "operationId": "list-anomalies",
                 "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only anomalies that were not resolved yet",
+                        "name": "open",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "Offset",
                         "name": "offset",
-                        "in": "query",
-                        "required": true
+                        "in": "query"
                     },
                     {
                         "type": "integer",
@@ -68,12 +81,7 @@ var doc = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/db.Rating"
-                            }
-                        },
-                        "headers": {
-                            "BasicHeader": {
-                                "type": "object"
+                                "$ref": "#/definitions/db.Anomaly"
                             }
                         }
                     },
@@ -83,6 +91,83 @@ var doc = `{
                             "$ref": "#/definitions/db.HTTPError400"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError403"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/anomalies/{id}/resolve": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "close anomaly, so that summary of a frozen station includes all ratings again",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Resolve an anomaly",
+                "operationId": "resolve-anomaly",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Anomaly ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.Anomaly"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError403"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -96,9 +181,67 @@ var doc = `{
                         }
                     }
                 }
+            }
+        },
+        "/admin/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get all API keys without their secrets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get all API keys",
+                "operationId": "list-api-keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/db.APIKey"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError403"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError500"
+                        }
+                    }
+                }
             },
             "post": {
-                "description": "create rating",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create API key for service-to-service callers, the key is only returned once",
                 "consumes": [
                     "application/json"
                 ],
@@ -106,18 +249,18 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
+                    "admin"
                 ],
-                "summary": "Create a new rating",
-                "operationId": "create-rating",
+                "summary": "Create a new API key",
+                "operationId": "create-api-key",
                 "parameters": [
                     {
-                        "description": "Rating parametres",
+                        "description": "API key parametres",
                         "name": "message",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/db.CreateRatingParam"
+                            "$ref": "#/definitions/db.CreateAPIKeyParam"
                         }
                     }
                 ],
@@ -125,13 +268,25 @@ var doc = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/db.Rating"
+                            "$ref": "#/definitions/db.IssuedAPIKey"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/db.HTTPError404"
+                            "$ref": "#/definitions/db.HTTPError400"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError403"
                         }
                     },
                     "500": {
@@ -143,43 +298,45 @@ var doc = `{
                 }
             }
         },
-        "/ratings/station/{id}": {
-            "get": {
-                "description": "get rating by station",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+        "/admin/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "revoke API key",
                 "tags": [
-                    "ratings"
+                    "admin"
                 ],
-                "summary": "Get all ratings of a single station by its ID",
-                "operationId": "get-rating-by-station",
+                "summary": "Revoke an API key",
+                "operationId": "revoke-api-key",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID of station",
+                        "description": "API key ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": ""
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/db.Rating"
-                            }
+                            "$ref": "#/definitions/db.HTTPError401"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/db.HTTPError400"
+                            "$ref": "#/definitions/db.HTTPError403"
                         }
                     },
                     "404": {
@@ -197,24 +354,29 @@ var doc = `{
                 }
             }
         },
-        "/ratings/{id}": {
-            "get": {
-                "description": "get rating by ID",
-                "consumes": [
-                    "application/json"
+        "/admin/api-keys/{id}/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "replace secret of an API key, the old secret stops working immediately",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
+                    "admin"
                 ],
-                "summary": "Get a rating by its ID",
-                "operationId": "get-rating-by-int",
+                "summary": "Rotate an API key",
+                "operationId": "rotate-api-key",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Rating ID",
+                        "description": "API key ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -224,7 +386,19 @@ var doc = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/db.Rating"
+                            "$ref": "#/definitions/db.IssuedAPIKey"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError401"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError403"
                         }
                     },
                     "404": {
@@ -240,9 +414,19 @@ var doc = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "update rating",
+            }
+        },
+        "/moderation/ratings": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get pending ratings, oldest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -250,33 +434,33 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
+                    "moderation"
                 ],
-                "summary": "Update a rating",
-                "operationId": "update-rating",
+                "summary": "Get ratings waiting for moderation",
+                "operationId": "get-pending-ratings",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Rating ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
                     },
                     {
-                        "description": "Rating parametres",
-                        "name": "message",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/db.UpdateRatingParam"
-                        }
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/db.Rating"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/db.Rating"
+                            }
                         }
                     },
                     "400": {
@@ -285,12 +469,6 @@ var doc = `{
                             "$ref": "#/definitions/db.HTTPError400"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/db.HTTPError404"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -298,9 +476,19 @@ var doc = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "delete rating",
+            }
+        },
+        "/moderation/ratings/{id}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "approve publishes the rating, remove hides it from public reads",
                 "consumes": [
                     "application/json"
                 ],
@@ -308,10 +496,9 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "ratings"
+                    "moderation"
                 ],
-                "summary": "Delete a rating",
-                "operationId": "delete-rating",
+                "summary": "Approve or remove a rating",
                 "parameters": [
                     {
                         "type": "integer",
@@ -322,18 +509,875 @@ var doc = `{
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": ""
-                    },
-                    "404": {
-                        "description": "Not Found",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/db.HTTPError404"
+                            "$ref": "#/definitions/db.Rating"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError400"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError404"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/ratings/{id}/remove": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "approve publishes the rating, remove hides it from public reads",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "moderation"
+                ],
+                "summary": "Approve or remove a rating",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rating ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.Rating"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError400"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError404"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/ratings": {
+            "get": {
+                "description": "get all ratings, or a batch of ratings by their IDs when ids parameter is set (returns RatingBatch)",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Get all ratings and comments",
+                "operationId": "get-all-ratings",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated list of rating IDs",
+                        "name": "ids",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/db.Rating"
+                            }
+                        },
+                        "headers": {
+                            "BasicHeader": {
+                                "type": "object"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError400"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError404"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError500"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create rating",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Create a new rating",
+                "operationId": "create-rating",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key that makes retries of the request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Rating parametres",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/db.CreateRatingParam"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/db.Rating"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError404"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError409"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError422"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/ratings/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "stream ratings filtered by station and creation date",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Export ratings as CSV, NDJSON or Parquet",
+                "operationId": "export-ratings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Format of export (csv, ndjson or parquet)",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of station",
+                        "name": "station_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Include ratings created at or after (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Include ratings created before (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError400"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/ratings/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "bulk import ratings, comments are checked by content filters, rows that fail validation or are rejected are reported and skipped",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Import ratings from CSV or NDJSON",
+                "operationId": "import-ratings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Format of request body (csv or ndjson)",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate and roll back without importing",
+                        "name": "dry_run",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError400"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/ratings/search": {
+            "get": {
+                "description": "full text search of comments, results are ordered by relevance and matches are highlighted with \u003cmark\u003e tags in HTML escaped comment",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Search comments of ratings",
+                "operationId": "search-ratings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query, supports quoted phrases, OR and -word",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Language of query (simple, english or slovenian), simple by default",
+                        "name": "language",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of station",
+                        "name": "station_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Include ratings created at or after (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Include ratings created before (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/db.SearchResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError400"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/ratings/station/{id}": {
+            "get": {
+                "description": "get rating by station",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Get all ratings of a single station by its ID",
+                "operationId": "get-rating-by-station",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of station",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/db.Rating"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError400"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError404"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/ratings/station/{id}/summary": {
+            "get": {
+                "description": "get rating summary by station",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Get rating summary of a single station by its ID",
+                "operationId": "get-rating-summary-by-station",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of station",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.StationSummary"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError400"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/ratings/{id}": {
+            "get": {
+                "description": "get rating by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Get a rating by its ID",
+                "operationId": "get-rating-by-int",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rating ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of cached rating",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.Rating"
+                        }
+                    },
+                    "304": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError404"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError500"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "update rating, If-Match header must contain current ETag of the rating",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Update a rating",
+                "operationId": "update-rating",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rating ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the rating",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Rating parametres",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/db.UpdateRatingParam"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/db.Rating"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError400"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError404"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError412"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError428"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError500"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "delete rating, If-Match header must contain current ETag of the rating",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Delete a rating",
+                "operationId": "delete-rating",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rating ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the rating",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError404"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError412"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError428"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError500"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "update only given fields of rating, If-Match header must contain current ETag of the rating",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Partially update a rating",
+                "operationId": "patch-rating",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rating ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the rating",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Rating parametres",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/db.PatchRatingParam"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.Rating"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError400"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError404"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError412"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError428"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError500"
+                        }
+                    }
+                }
+            }
+        },
+        "/stations/ratings:batchSummary": {
+            "post": {
+                "description": "get rating summaries of a batch of stations, stations without ratings are reported as missing",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ratings"
+                ],
+                "summary": "Get rating summaries of multiple stations",
+                "operationId": "batch-rating-summary-by-station",
+                "parameters": [
+                    {
+                        "description": "Station IDs",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/db.BatchStationSummaryParam"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.StationSummaryBatch"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/db.HTTPError400"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
                             "$ref": "#/definitions/db.HTTPError500"
                         }
                     }
@@ -342,6 +1386,101 @@ var doc = `{
         }
     },
     "definitions": {
+        "db.APIKey": {
+            "type": "object",
+            "properties": {
+                "api_key_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "rotated_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "db.Anomaly": {
+            "type": "object",
+            "properties": {
+                "anomaly_id": {
+                    "type": "integer"
+                },
+                "average_rating": {
+                    "type": "number"
+                },
+                "baseline_average": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "distance": {
+                    "type": "number"
+                },
+                "expected_count": {
+                    "type": "number"
+                },
+                "frozen": {
+                    "type": "boolean"
+                },
+                "rating_count": {
+                    "type": "integer"
+                },
+                "resolved_at": {
+                    "type": "string"
+                },
+                "station_id": {
+                    "type": "integer"
+                },
+                "window_end": {
+                    "type": "string"
+                },
+                "window_start": {
+                    "type": "string"
+                }
+            }
+        },
+        "db.BatchStationSummaryParam": {
+            "type": "object",
+            "properties": {
+                "station_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "db.CreateAPIKeyParam": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "db.CreateRatingParam": {
             "type": "object",
             "properties": {
@@ -373,6 +1512,24 @@ var doc = `{
                 }
             }
         },
+        "db.HTTPError401": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "authentication required"
+                }
+            }
+        },
+        "db.HTTPError403": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "missing scope ratings:write"
+                }
+            }
+        },
         "db.HTTPError404": {
             "type": "object",
             "properties": {
@@ -381,6 +1538,42 @@ var doc = `{
                 }
             }
         },
+        "db.HTTPError409": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "request with this idempotency key is still in progress"
+                }
+            }
+        },
+        "db.HTTPError412": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "rating was modified by another request"
+                }
+            }
+        },
+        "db.HTTPError422": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "idempotency key was already used with a different request"
+                }
+            }
+        },
+        "db.HTTPError428": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "If-Match header is required"
+                }
+            }
+        },
         "db.HTTPError500": {
             "type": "object",
             "properties": {
@@ -389,6 +1582,57 @@ var doc = `{
                 }
             }
         },
+        "db.ImportReport": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.RowError"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "imported": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "db.IssuedAPIKey": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "$ref": "#/definitions/db.APIKey"
+                },
+                "key": {
+                    "type": "string"
+                }
+            }
+        },
+        "db.PatchRatingParam": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "integer"
+                },
+                "station_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "db.Rating": {
             "type": "object",
             "properties": {
@@ -398,17 +1642,149 @@ var doc = `{
                 "created_at": {
                     "type": "string"
                 },
+                "moderation_reasons": {
+                    "description": "Why content filters masked comment or sent rating to moderation.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moderation.Reason"
+                    }
+                },
+                "rating": {
+                    "type": "integer"
+                },
+                "rating_id": {
+                    "type": "integer"
+                },
+                "sentiment": {
+                    "description": "Sentiment of comment from -1 to 1, null when comment has no known words.",
+                    "type": "number"
+                },
+                "station_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "db.RowError": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "db.SearchResult": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "highlight": {
+                    "type": "string"
+                },
+                "moderation_reasons": {
+                    "description": "Why content filters masked comment or sent rating to moderation.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/moderation.Reason"
+                    }
+                },
+                "rank": {
+                    "type": "number"
+                },
                 "rating": {
                     "type": "integer"
                 },
                 "rating_id": {
                     "type": "integer"
                 },
+                "sentiment": {
+                    "description": "Sentiment of comment from -1 to 1, null when comment has no known words.",
+                    "type": "number"
+                },
                 "station_id": {
                     "type": "integer"
                 },
+                "status": {
+                    "type": "string"
+                },
                 "user_id": {
                     "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "db.StationSummary": {
+            "type": "object",
+            "properties": {
+                "average_rating": {
+                    "type": "number"
+                },
+                "average_sentiment": {
+                    "description": "Average sentiment of scored comments, null when there are none.",
+                    "type": "number"
+                },
+                "five_star": {
+                    "type": "integer"
+                },
+                "four_star": {
+                    "type": "integer"
+                },
+                "frozen": {
+                    "description": "Ratings since the start of an open anomaly of the station are left out.",
+                    "type": "boolean"
+                },
+                "one_star": {
+                    "type": "integer"
+                },
+                "rating_count": {
+                    "type": "integer"
+                },
+                "sentiment_mismatches": {
+                    "description": "Ratings whose stars disagree with sentiment of their comment.",
+                    "type": "integer"
+                },
+                "station_id": {
+                    "type": "integer"
+                },
+                "three_star": {
+                    "type": "integer"
+                },
+                "two_star": {
+                    "type": "integer"
+                }
+            }
+        },
+        "db.StationSummaryBatch": {
+            "type": "object",
+            "properties": {
+                "missing_station_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "summaries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/db.StationSummary"
+                    }
                 }
             }
         },
@@ -428,6 +1804,32 @@ var doc = `{
                     "type": "integer"
                 }
             }
+        },
+        "moderation.Reason": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "filter": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,53 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"rating-service/config"
+	"time"
+)
+
+const (
+	PublisherLog     = "log"
+	PublisherWebhook = "webhook"
+	PublisherRedis   = "redis"
+	PublisherNone    = "none"
+)
+
+// Something that happened in the service, e.g. an alert about a station.
+type Event struct {
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+func New(eventType string, data interface{}) Event {
+	return Event{Type: eventType, Time: time.Now().UTC(), Data: data}
+}
+
+// Delivers events to other systems.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// Creates publisher selected in configuration. Events are logged by default.
+func NewPublisher(config config.Config) (Publisher, error) {
+	switch config.AlertPublisher {
+	case "", PublisherLog:
+		return Log{}, nil
+	case PublisherWebhook:
+		return NewWebhook(config.AlertWebhookURL), nil
+	case PublisherRedis:
+		return NewRedis(config.RedisAddress, config.RedisPassword, config.RedisDB, config.AlertChannel), nil
+	case PublisherNone:
+		return noop{}, nil
+	default:
+		return nil, fmt.Errorf("unknown alert publisher %q", config.AlertPublisher)
+	}
+}
+
+type noop struct{}
+
+func (noop) Publish(ctx context.Context, event Event) error {
+	return nil
+}
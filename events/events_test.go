@@ -0,0 +1,52 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"rating-service/config"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook(t *testing.T) {
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	event := New("test.event", map[string]interface{}{"station_id": float64(1)})
+	require.NoError(t, NewWebhook(server.URL).Publish(context.Background(), event))
+	require.Equal(t, "test.event", received.Type)
+	require.Equal(t, event.Data, received.Data)
+	require.True(t, event.Time.Equal(received.Time))
+}
+
+func TestWebhookError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := NewWebhook(server.URL).Publish(context.Background(), New("test.event", nil))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "500")
+}
+
+func TestNewPublisher(t *testing.T) {
+	settings := config.Default()
+
+	publisher, err := NewPublisher(settings)
+	require.NoError(t, err)
+	require.IsType(t, Log{}, publisher)
+	require.NoError(t, publisher.Publish(context.Background(), New("test.event", nil)))
+
+	settings.AlertPublisher = "kafka"
+	_, err = NewPublisher(settings)
+	require.Error(t, err)
+}
@@ -0,0 +1,16 @@
+package events
+
+import (
+	"context"
+	"rating-service/logging"
+
+	"go.uber.org/zap"
+)
+
+// Writes events to the log as warnings.
+type Log struct{}
+
+func (Log) Publish(ctx context.Context, event Event) error {
+	logging.FromContext(ctx).Warn("Alert", zap.String("type", event.Type), zap.Time("time", event.Time), zap.Any("data", event.Data))
+	return nil
+}
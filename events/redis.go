@@ -0,0 +1,37 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Publishes events as JSON on a Redis channel.
+type Redis struct {
+	client  *redis.Client
+	channel string
+}
+
+func NewRedis(address, password string, db int, channel string) *Redis {
+	client := redis.NewClient(&redis.Options{
+		Addr:     address,
+		Password: password,
+		DB:       db,
+	})
+
+	return &Redis{client: client, channel: channel}
+}
+
+func (r *Redis) Publish(ctx context.Context, event Event) error {
+	message, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Publish(ctx, r.channel, message).Err()
+}
+
+func (r *Redis) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
@@ -0,0 +1,123 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"rating-service/auth"
+	"rating-service/db"
+	"rating-service/rbac"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const apiKeyMetadata = "x-api-key"
+
+// Context key under which authenticated caller is stored.
+type principalKey struct{}
+
+// Permissions required by methods. Methods that are not listed are denied.
+var methodPermissions = map[string]rbac.Permission{
+	"/pb.RatingService/GetByID":           rbac.Public,
+	"/pb.RatingService/GetAll":            rbac.Public,
+	"/pb.RatingService/GetAllByStation":   rbac.Public,
+	"/pb.RatingService/GetStationSummary": rbac.Public,
+	"/pb.RatingService/Create":            rbac.WriteRatings,
+	"/pb.RatingService/Update":            rbac.WriteRatings,
+	"/pb.RatingService/Delete":            rbac.WriteRatings,
+	"/grpc.health.v1.Health/Check":        rbac.Public,
+}
+
+// Interceptor that checks permissions of callers with an API key or user JWT.
+func (server *Server) authorize(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	permission, ok := methodPermissions[info.FullMethod]
+	if !ok {
+		rbac.RecordDenied(info.FullMethod, permission, rbac.ReasonUndeclared)
+		return nil, status.Error(codes.PermissionDenied, "access denied")
+	}
+
+	if permission == rbac.Public {
+		return handler(ctx, req)
+	}
+
+	principal, err := server.authenticate(ctx)
+	if err != nil {
+		rbac.RecordDenied(info.FullMethod, permission, rbac.ReasonUnauthenticated)
+		return nil, err
+	}
+
+	if !principal.Permissions.Has(permission) {
+		rbac.RecordDenied(info.FullMethod, permission, rbac.ReasonForbidden)
+		return nil, status.Errorf(codes.PermissionDenied, "missing permission %s", permission)
+	}
+
+	return handler(context.WithValue(ctx, principalKey{}, principal), req)
+}
+
+// Checks that caller may write ratings of user with given ID.
+func authorizeOwner(ctx context.Context, userID int64) error {
+	principal, _ := ctx.Value(principalKey{}).(auth.Principal)
+	if principal.MayWriteRatingsOf(userID) {
+		return nil
+	}
+
+	method, _ := grpc.Method(ctx)
+	rbac.RecordDenied(method, rbac.WriteAnyRatings, rbac.ReasonForbidden)
+	return status.Error(codes.PermissionDenied, "ratings of other users can't be written")
+}
+
+// Checks that caller may change given version of stored rating. The rating is only
+// read for callers that may write their own ratings only.
+func (server *Server) authorizeRatingOwner(ctx context.Context, id, version int64) error {
+	principal, _ := ctx.Value(principalKey{}).(auth.Principal)
+	if principal.Permissions.Has(rbac.WriteAnyRatings) {
+		return nil
+	}
+
+	current, err := server.store.GetByID(db.WithPrimary(ctx), id)
+	if err != nil {
+		return queryError(err)
+	}
+	if err := authorizeOwner(ctx, current.User_id); err != nil {
+		return err
+	}
+
+	// Owner was checked for the current version, writes of any other fail.
+	if current.Version != version {
+		return queryError(db.ErrVersionMismatch)
+	}
+
+	return nil
+}
+
+func (server *Server) authenticate(ctx context.Context) (auth.Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var principal auth.Principal
+	var err error
+
+	if keys := md.Get(apiKeyMetadata); len(keys) > 0 {
+		principal, err = server.auth.APIKey(ctx, keys[0])
+	} else if values := md.Get("authorization"); len(values) > 0 {
+		token := strings.TrimPrefix(values[0], "Bearer ")
+		if token == values[0] {
+			err = auth.ErrInvalidToken
+		} else {
+			principal, err = server.auth.Token(token)
+		}
+	} else {
+		return principal, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	switch {
+	case errors.Is(err, auth.ErrInvalidAPIKey) || errors.Is(err, auth.ErrInvalidToken):
+		return principal, status.Error(codes.Unauthenticated, err.Error())
+	case err != nil:
+		return principal, status.Errorf(codes.Internal, "failed to authenticate: %s", err)
+	}
+
+	return principal, nil
+}
@@ -0,0 +1,49 @@
+package gapi
+
+import (
+	"rating-service/db"
+	"rating-service/pb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func convertRating(rating db.Rating) *pb.Rating {
+	return &pb.Rating{
+		RatingId:  rating.ID,
+		StationId: rating.Station_id,
+		UserId:    rating.User_id,
+		Rating:    rating.Rating,
+		Comment:   rating.Comment,
+		CreatedAt: timestamppb.New(rating.CreatedAt),
+		Version:   rating.Version,
+	}
+}
+
+func convertRatings(ratings []db.Rating) *pb.ListRatingsResponse {
+	result := &pb.ListRatingsResponse{
+		Ratings: make([]*pb.Rating, 0, len(ratings)),
+	}
+
+	for _, rating := range ratings {
+		result.Ratings = append(result.Ratings, convertRating(rating))
+	}
+
+	return result
+}
+
+func convertStationSummary(summary db.StationSummary) *pb.StationSummary {
+	return &pb.StationSummary{
+		StationId:     summary.StationID,
+		RatingCount:   summary.RatingCount,
+		AverageRating: summary.AverageRating,
+		OneStar:       summary.OneStar,
+		TwoStar:       summary.TwoStar,
+		ThreeStar:     summary.ThreeStar,
+		FourStar:      summary.FourStar,
+		FiveStar:      summary.FiveStar,
+
+		AverageSentiment:    summary.AverageSentiment,
+		SentimentMismatches: summary.SentimentMismatches,
+		Frozen:              summary.Frozen,
+	}
+}
@@ -0,0 +1,41 @@
+package gapi
+
+import (
+	"rating-service/db"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestConvertStationSummary(t *testing.T) {
+	sentiment := -0.4
+	summary := convertStationSummary(db.StationSummary{
+		StationID:           7,
+		RatingCount:         3,
+		AverageRating:       2,
+		OneStar:             1,
+		TwoStar:             1,
+		ThreeStar:           1,
+		AverageSentiment:    &sentiment,
+		SentimentMismatches: 1,
+		Frozen:              true,
+	})
+	require.Equal(t, int64(7), summary.GetStationId())
+	require.Equal(t, int64(3), summary.GetRatingCount())
+	require.Equal(t, -0.4, summary.GetAverageSentiment())
+	require.Equal(t, int64(1), summary.GetSentimentMismatches())
+	require.True(t, summary.GetFrozen())
+
+	// Fields survive encoding.
+	data, err := proto.Marshal(summary)
+	require.NoError(t, err)
+	decoded := summary.ProtoReflect().New().Interface()
+	require.NoError(t, proto.Unmarshal(data, decoded))
+	require.True(t, proto.Equal(summary, decoded))
+
+	// Unknown sentiment stays unset rather than zero.
+	summary = convertStationSummary(db.StationSummary{StationID: 7})
+	require.Nil(t, summary.AverageSentiment)
+	require.False(t, summary.GetFrozen())
+}
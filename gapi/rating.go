@@ -0,0 +1,186 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"rating-service/db"
+	"rating-service/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) GetByID(ctx context.Context, req *pb.GetRatingRequest) (*pb.Rating, error) {
+
+	// Check if request has a valid ID.
+	if req.GetId() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "id must be a positive number")
+	}
+
+	// Execute query.
+	result, err := server.store.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, queryError(err)
+	}
+
+	// Ratings that are not public are only available to moderators over HTTP.
+	if result.Status != db.StatusPublished {
+		return nil, status.Error(codes.NotFound, "rating not found")
+	}
+
+	return convertRating(result), nil
+}
+
+func (server *Server) GetAll(ctx context.Context, req *pb.ListRatingsRequest) (*pb.ListRatingsResponse, error) {
+
+	// Check if request has parameters offset and limit for pagination.
+	if req.GetOffset() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset must not be negative")
+	}
+	if req.GetLimit() < 1 || req.GetLimit() > 20 {
+		return nil, status.Error(codes.InvalidArgument, "limit must be between 1 and 20")
+	}
+
+	arg := db.ListRatingParam{
+		Offset: req.GetOffset(),
+		Limit:  req.GetLimit(),
+	}
+
+	// Execute query.
+	result, err := server.store.GetAll(ctx, arg)
+	if err != nil {
+		return nil, queryError(err)
+	}
+
+	return convertRatings(result), nil
+}
+
+func (server *Server) Create(ctx context.Context, req *pb.CreateRatingRequest) (*pb.Rating, error) {
+
+	// Users create ratings of their own.
+	if err := authorizeOwner(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
+
+	arg := db.CreateRatingParam{
+		Station_id: req.GetStationId(),
+		User_id:    req.GetUserId(),
+		Rating:     req.GetRating(),
+		Comment:    req.GetComment(),
+	}
+
+	// Execute query.
+	result, err := server.store.Create(ctx, arg)
+	if err != nil {
+		return nil, queryError(err)
+	}
+
+	return convertRating(result), nil
+}
+
+func (server *Server) Update(ctx context.Context, req *pb.UpdateRatingRequest) (*pb.Rating, error) {
+
+	// Check if request has a valid ID and version.
+	if req.GetId() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "id must be a positive number")
+	}
+	if req.GetVersion() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "version must be a positive number")
+	}
+
+	// Users change their own ratings and can't give them to another user.
+	if err := server.authorizeRatingOwner(ctx, req.GetId(), req.GetVersion()); err != nil {
+		return nil, err
+	}
+	if err := authorizeOwner(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
+
+	arg := db.UpdateRatingParam{
+		Station_id: req.GetStationId(),
+		User_id:    req.GetUserId(),
+		Rating:     req.GetRating(),
+		Comment:    req.GetComment(),
+		Version:    req.GetVersion(),
+	}
+
+	// Execute query.
+	result, err := server.store.Update(ctx, arg, req.GetId())
+	if err != nil {
+		return nil, queryError(err)
+	}
+
+	return convertRating(result), nil
+}
+
+func (server *Server) Delete(ctx context.Context, req *pb.DeleteRatingRequest) (*pb.DeleteRatingResponse, error) {
+
+	// Check if request has a valid ID and version.
+	if req.GetId() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "id must be a positive number")
+	}
+	if req.GetVersion() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "version must be a positive number")
+	}
+
+	// Users delete their own ratings.
+	if err := server.authorizeRatingOwner(ctx, req.GetId(), req.GetVersion()); err != nil {
+		return nil, err
+	}
+
+	// Execute query.
+	if err := server.store.DeleteVersion(ctx, req.GetId(), req.GetVersion()); err != nil {
+		return nil, queryError(err)
+	}
+
+	return &pb.DeleteRatingResponse{}, nil
+}
+
+func (server *Server) GetAllByStation(ctx context.Context, req *pb.GetStationRequest) (*pb.ListRatingsResponse, error) {
+
+	// Check if request has a valid station ID.
+	if req.GetStationId() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "station_id must be a positive number")
+	}
+
+	// Execute query.
+	result, err := server.ratings.GetAllByStation(ctx, req.GetStationId())
+	if err != nil {
+		return nil, queryError(err)
+	}
+
+	return convertRatings(result), nil
+}
+
+func (server *Server) GetStationSummary(ctx context.Context, req *pb.GetStationRequest) (*pb.StationSummary, error) {
+
+	// Check if request has a valid station ID.
+	if req.GetStationId() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "station_id must be a positive number")
+	}
+
+	// Execute query.
+	result, err := server.ratings.GetStationSummary(ctx, req.GetStationId())
+	if err != nil {
+		return nil, queryError(err)
+	}
+
+	return convertStationSummary(result), nil
+}
+
+// Maps database errors to gRPC status codes.
+func queryError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return status.Error(codes.NotFound, "rating not found")
+	}
+	if errors.Is(err, db.ErrVersionMismatch) {
+		return status.Error(codes.FailedPrecondition, "rating was modified by another request")
+	}
+	var rejected *db.RejectedError
+	if errors.As(err, &rejected) {
+		return status.Error(codes.InvalidArgument, rejected.Error())
+	}
+
+	return status.Errorf(codes.Internal, "failed to execute query: %s", err)
+}
@@ -0,0 +1,72 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"rating-service/auth"
+	"rating-service/cache"
+	"rating-service/config"
+	"rating-service/db"
+	"rating-service/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type Server struct {
+	pb.UnimplementedRatingServiceServer
+	config  config.Config
+	store   *db.Store
+	ratings *cache.Ratings
+	auth    *auth.Authenticator
+	grpc    *grpc.Server
+}
+
+func NewServer(config config.Config, store *db.Store, ratings *cache.Ratings) (*Server, error) {
+
+	server := &Server{
+		config:  config,
+		store:   store,
+		ratings: ratings,
+		auth:    auth.NewAuthenticator(store, config.JWTSecret),
+	}
+
+	// Register rating service.
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(server.authorize))
+	pb.RegisterRatingServiceServer(grpcServer, server)
+
+	// Register standard health service.
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(pb.RatingService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	server.grpc = grpcServer
+	return server, nil
+}
+
+func (server *Server) Start(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	return server.grpc.Serve(listener)
+}
+
+// Stops accepting requests and waits for active ones to finish. Remaining requests
+// are cancelled when ctx is done.
+func (server *Server) Shutdown(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		server.grpc.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		server.grpc.Stop()
+	}
+}
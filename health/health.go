@@ -0,0 +1,113 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "UP"
+	StatusDown = "DOWN"
+
+	defaultTimeout = 2 * time.Second
+)
+
+// Checks a dependency. Returned details are reported even when check fails.
+type Check func(ctx context.Context) (details map[string]interface{}, err error)
+
+// Dependency that can be pinged.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Returns check that pings a dependency.
+func Ping(pinger Pinger) Check {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		return nil, pinger.Ping(ctx)
+	}
+}
+
+// Result of a dependency check.
+type Result struct {
+	Status  string                 `json:"status"`
+	Latency string                 `json:"latency"`
+	Error   string                 `json:"error,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Results of all checks. Service is up only when all dependencies are up.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Runs registered dependency checks.
+type Checker struct {
+	checks  []namedCheck
+	timeout time.Duration
+}
+
+func NewChecker() *Checker {
+	return &Checker{timeout: defaultTimeout}
+}
+
+// Registers a dependency check. Checks must be registered before they are run.
+func (c *Checker) Register(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Runs all checks concurrently, each with its own timeout.
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{
+		Status: StatusUp,
+		Checks: make(map[string]Result, len(c.checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func(check namedCheck) {
+			defer wg.Done()
+			result := c.run(ctx, check.check)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[check.name] = result
+			if result.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}(check)
+	}
+
+	wg.Wait()
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := check(ctx)
+
+	result := Result{
+		Status:  StatusUp,
+		Latency: time.Since(start).String(),
+		Details: details,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,53 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type pinger struct {
+	err error
+}
+
+func (p pinger) Ping(ctx context.Context) error {
+	return p.err
+}
+
+func TestChecker(t *testing.T) {
+	checker := NewChecker()
+	checker.Register("database", Ping(pinger{}))
+	checker.Register("pool", func(ctx context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{"in_use": 1}, nil
+	})
+
+	report := checker.Run(context.Background())
+	require.Equal(t, StatusUp, report.Status)
+	require.Len(t, report.Checks, 2)
+	require.Equal(t, StatusUp, report.Checks["database"].Status)
+	require.NotEmpty(t, report.Checks["database"].Latency)
+	require.Equal(t, 1, report.Checks["pool"].Details["in_use"])
+
+	checker.Register("cache", Ping(pinger{err: errors.New("connection refused")}))
+
+	report = checker.Run(context.Background())
+	require.Equal(t, StatusDown, report.Status)
+	require.Equal(t, StatusUp, report.Checks["database"].Status)
+	require.Equal(t, StatusDown, report.Checks["cache"].Status)
+	require.Equal(t, "connection refused", report.Checks["cache"].Error)
+}
+
+func TestCheckerTimeout(t *testing.T) {
+	checker := NewChecker()
+	checker.timeout = 0
+	checker.Register("database", func(ctx context.Context) (map[string]interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	report := checker.Run(context.Background())
+	require.Equal(t, StatusDown, report.Status)
+	require.Equal(t, context.DeadlineExceeded.Error(), report.Checks["database"].Error)
+}
@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type contextKey struct{}
+
+// Level of the global logger, can be changed while the service runs.
+var level = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+
+// Creates a logger that writes JSON to stderr and makes it the global logger.
+// Level is one of debug, info, warn and error, info is used by default.
+func New(logLevel string) (*zap.Logger, error) {
+	if err := SetLevel(logLevel); err != nil {
+		return nil, err
+	}
+
+	config := zap.NewProductionConfig()
+	config.Level = level
+	config.EncoderConfig.TimeKey = "time"
+	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	logger, err := config.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	zap.ReplaceGlobals(logger)
+	return logger, nil
+}
+
+// Changes level of the global logger. Level is one of debug, info, warn and
+// error, info is used when it is empty.
+func SetLevel(logLevel string) error {
+	if logLevel == "" {
+		logLevel = "info"
+	}
+
+	return level.UnmarshalText([]byte(logLevel))
+}
+
+// Returns context that carries given logger.
+func WithContext(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// Returns logger carried by context, or the global logger.
+func FromContext(ctx context.Context) *zap.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+		return logger
+	}
+
+	return zap.L()
+}
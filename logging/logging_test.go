@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNew(t *testing.T) {
+	logger, err := New("")
+	require.NoError(t, err)
+	require.True(t, logger.Core().Enabled(zapcore.InfoLevel))
+	require.False(t, logger.Core().Enabled(zapcore.DebugLevel))
+	require.Equal(t, logger, zap.L())
+
+	logger, err = New("debug")
+	require.NoError(t, err)
+	require.True(t, logger.Core().Enabled(zapcore.DebugLevel))
+
+	_, err = New("verbose")
+	require.Error(t, err)
+}
+
+func TestSetLevel(t *testing.T) {
+	logger, err := New("info")
+	require.NoError(t, err)
+	require.False(t, logger.Core().Enabled(zapcore.DebugLevel))
+
+	require.NoError(t, SetLevel("debug"))
+	require.True(t, logger.Core().Enabled(zapcore.DebugLevel))
+
+	require.Error(t, SetLevel("verbose"))
+	require.True(t, logger.Core().Enabled(zapcore.DebugLevel))
+}
+
+func TestFromContext(t *testing.T) {
+	require.Equal(t, zap.L(), FromContext(context.Background()))
+
+	logger := zap.NewNop()
+	require.Equal(t, logger, FromContext(WithContext(context.Background(), logger)))
+}
@@ -1,11 +1,6 @@
 package main
 
-import (
-	"log"
-	"rating-service/config"
-	"rating-service/db"
-	"rating-service/server"
-)
+import "rating-service/cmd"
 
 // @title rating-service API
 // @version 1.0
@@ -23,28 +18,14 @@ import (
 // @BasePath /
 // @schemes http
 
-func main() {
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 
-	// Load configuration settings.
-	config, err := config.New(".")
-	if err != nil {
-		log.Fatal("Failed to load config: ", err)
-	}
-
-	// Connect to the database.
-	store, err := db.Connect(config.DBDriver, config.DBSource)
-	if err != nil {
-		log.Fatal("Failed to connect to database: ", err)
-	}
-
-	// Create a server and setup routes.
-	server, err := server.NewServer(config, store)
-	if err != nil {
-		log.Fatal("Failed to create a server: ", err)
-	}
-
-	// Start a server.
-	if err := server.Start(config.ServerAddress); err != nil {
-		log.Fatal("Failed to start a server: ", err)
-	}
+func main() {
+	cmd.Execute()
 }
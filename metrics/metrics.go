@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry of all service metrics, served on /metrics.
+var Registry = prometheus.NewRegistry()
+
+// Registers metrics on service registry.
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Returns handler that serves metrics from service registry.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// HTTP metrics.
+var (
+	RequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rating_service_http_request_duration_seconds",
+		Help:    "Duration of HTTP requests by method, route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	RequestSize = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rating_service_http_request_size_bytes",
+		Help:    "Size of HTTP request bodies by method, route and status.",
+		Buckets: prometheus.ExponentialBuckets(100, 10, 6),
+	}, []string{"method", "route", "status"})
+
+	ResponseSize = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "rating_service_http_response_size_bytes",
+		Help:    "Size of HTTP response bodies by method, route and status.",
+		Buckets: prometheus.ExponentialBuckets(100, 10, 6),
+	}, []string{"method", "route", "status"})
+)
+
+// Database metrics.
+var QueryDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "rating_service_db_query_duration_seconds",
+	Help:    "Duration of store methods by method.",
+	Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+}, []string{"method"})
+
+// Cache metrics.
+var CacheLookups = factory.NewCounterVec(prometheus.CounterOpts{
+	Name: "rating_service_cache_lookups_total",
+	Help: "Number of cache lookups by cached resource and result.",
+}, []string{"resource", "result"})
+
+// Access control metrics.
+var AuthorizationDenied = factory.NewCounterVec(prometheus.CounterOpts{
+	Name: "rating_service_authorization_denied_total",
+	Help: "Number of requests denied by access control by route, required permission and reason.",
+}, []string{"route", "permission", "reason"})
+
+// Business metrics.
+var (
+	RatingsCreated = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "rating_service_ratings_created_total",
+		Help: "Number of created ratings by star value.",
+	}, []string{"stars"})
+
+	RatingsDeleted = factory.NewCounter(prometheus.CounterOpts{
+		Name: "rating_service_ratings_deleted_total",
+		Help: "Number of deleted ratings.",
+	})
+
+	ModerationActions = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "rating_service_moderation_actions_total",
+		Help: "Number of moderation actions by action.",
+	}, []string{"action"})
+
+	AnomaliesDetected = factory.NewCounter(prometheus.CounterOpts{
+		Name: "rating_service_anomalies_detected_total",
+		Help: "Number of detected anomalies of station ratings.",
+	})
+)
@@ -0,0 +1,236 @@
+package moderation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// Finds words from a list of profanities of one language. Entries ending with *
+// also match words that start with them, e.g. inflected forms.
+type Wordlist struct {
+	Language string
+	words    map[string]bool
+	prefixes []string
+}
+
+func NewWordlist(language string, words []string) Wordlist {
+	wordlist := Wordlist{Language: language, words: make(map[string]bool)}
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		switch {
+		case word == "", strings.HasPrefix(word, "#"):
+		case strings.HasSuffix(word, "*"):
+			wordlist.prefixes = append(wordlist.prefixes, strings.TrimSuffix(word, "*"))
+		default:
+			wordlist.words[word] = true
+		}
+	}
+
+	return wordlist
+}
+
+func (w Wordlist) Name() string {
+	return "profanity"
+}
+
+func (w Wordlist) Find(comment string) []Match {
+	var matches []Match
+	for _, loc := range wordPattern.FindAllStringIndex(comment, -1) {
+		word := strings.ToLower(comment[loc[0]:loc[1]])
+		if w.matches(word) {
+			matches = append(matches, Match{
+				Start:  loc[0],
+				End:    loc[1],
+				Reason: fmt.Sprintf("word %q (%s)", word, w.Language),
+			})
+		}
+	}
+
+	return matches
+}
+
+func (w Wordlist) matches(word string) bool {
+	if w.words[word] {
+		return true
+	}
+
+	for _, prefix := range w.prefixes {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s]+|\b[a-z0-9-]+(?:\.[a-z0-9-]+)*\.(?:com|net|org|info|biz|io|eu|si|hr|at|de|it|ru)\b(?:/[^\s]*)?`)
+
+// Finds URLs and domain names.
+type Links struct{}
+
+func (Links) Name() string {
+	return "link"
+}
+
+func (Links) Find(comment string) []Match {
+	return findPattern(linkPattern, comment, "link")
+}
+
+// Runs of digits separated by single spaces, dots, slashes or dashes.
+var numberPattern = regexp.MustCompile(`\+?\d(?:[\s./-]?\d)*`)
+
+// Numbers that start with a country code or a leading 0, followed by groups of
+// 2 to 4 digits, e.g. "041 123 456" or "+386 1 234 5678". A part of a number
+// after a dot, slash or dash is not a phone number.
+var phonePattern = regexp.MustCompile(`(?:^|\s)((?:\+\d{1,3}|0\d{0,3})(?:[\s./-]?\d{2,4}){2,4})\b`)
+
+// ISO and day-month-year dates, e.g. "2021-12-01" or "01/12/2021".
+var datePattern = regexp.MustCompile(`\b(?:\d{4}[./-]\d{1,2}[./-]\d{1,2}|\d{1,2}[./-]\d{1,2}[./-]\d{2,4})\b`)
+
+const (
+	minPhoneDigits = 8
+	maxPhoneDigits = 15
+)
+
+// Finds phone numbers. Numbers that contain a date and numbers without a country
+// code or a leading 0, e.g. amounts and reference numbers, are not phone numbers.
+type PhoneNumbers struct{}
+
+func (PhoneNumbers) Name() string {
+	return "phone"
+}
+
+func (PhoneNumbers) Find(comment string) []Match {
+	var matches []Match
+	for _, run := range numberPattern.FindAllStringIndex(comment, -1) {
+		number := comment[run[0]:run[1]]
+		if datePattern.MatchString(number) {
+			continue
+		}
+
+		for _, loc := range phonePattern.FindAllStringSubmatchIndex(number, -1) {
+			loc = loc[2:]
+			phone := number[loc[0]:loc[1]]
+			if digits := countDigits(phone); digits < minPhoneDigits || digits > maxPhoneDigits {
+				continue
+			}
+			matches = append(matches, Match{
+				Start:  run[0] + loc[0],
+				End:    run[0] + loc[1],
+				Reason: fmt.Sprintf("phone number %q", phone),
+			})
+		}
+	}
+
+	return matches
+}
+
+func countDigits(text string) int {
+	count := 0
+	for _, r := range text {
+		if r >= '0' && r <= '9' {
+			count++
+		}
+	}
+
+	return count
+}
+
+func findPattern(pattern *regexp.Regexp, comment, reason string) []Match {
+	var matches []Match
+	for _, loc := range pattern.FindAllStringIndex(comment, -1) {
+		matches = append(matches, Match{
+			Start:  loc[0],
+			End:    loc[1],
+			Reason: fmt.Sprintf("%s %q", reason, comment[loc[0]:loc[1]]),
+		})
+	}
+
+	return matches
+}
+
+// Finds runs of more than Max equal characters, e.g. "!!!!!!!" or "sooooo".
+type RepeatedCharacters struct {
+	Max int
+}
+
+func (RepeatedCharacters) Name() string {
+	return "repeated_characters"
+}
+
+func (f RepeatedCharacters) Find(comment string) []Match {
+	var matches []Match
+
+	start, count := 0, 0
+	var previous rune
+	for i, r := range comment + "\x00" {
+		if r == previous && i < len(comment) {
+			count++
+			continue
+		}
+
+		if count > f.Max {
+			matches = append(matches, Match{
+				Start:  start,
+				End:    i,
+				Reason: fmt.Sprintf("%q repeated %d times", previous, count),
+			})
+		}
+		start, count, previous = i, 1, r
+	}
+
+	return matches
+}
+
+// Finds phrases that are never allowed, regardless of case.
+type Blocklist struct {
+	phrases []string
+}
+
+func NewBlocklist(phrases []string) Blocklist {
+	var blocklist Blocklist
+	for _, phrase := range phrases {
+		if phrase = strings.ToLower(strings.TrimSpace(phrase)); phrase != "" {
+			blocklist.phrases = append(blocklist.phrases, phrase)
+		}
+	}
+
+	return blocklist
+}
+
+func (Blocklist) Name() string {
+	return "blocklist"
+}
+
+func (b Blocklist) Find(comment string) []Match {
+	lower := strings.ToLower(comment)
+
+	// Lower casing rarely changes length of text, then the whole comment is matched.
+	sameLength := len(lower) == len(comment)
+
+	var matches []Match
+	for _, phrase := range b.phrases {
+		for offset := 0; ; {
+			i := strings.Index(lower[offset:], phrase)
+			if i < 0 {
+				break
+			}
+
+			start, end := offset+i, offset+i+len(phrase)
+			offset = end
+			if !sameLength {
+				start, end = 0, len(comment)
+			}
+			matches = append(matches, Match{Start: start, End: end, Reason: fmt.Sprintf("phrase %q", phrase)})
+
+			if !sameLength {
+				break
+			}
+		}
+	}
+
+	return matches
+}
@@ -0,0 +1,164 @@
+package moderation
+
+import (
+	"fmt"
+	"rating-service/config"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// What happens with a comment that matched a filter.
+type Action string
+
+const (
+	ActionNone     Action = "none"
+	ActionMask     Action = "mask"
+	ActionModerate Action = "moderate"
+	ActionReject   Action = "reject"
+)
+
+// Actions ordered by severity, the most severe action of all matches applies.
+var severity = map[Action]int{
+	ActionNone:     0,
+	ActionMask:     1,
+	ActionModerate: 2,
+	ActionReject:   3,
+}
+
+// Part of a comment that matched a filter. Start and End are byte offsets.
+type Match struct {
+	Start  int
+	End    int
+	Reason string
+}
+
+// Finds unwanted content in comments.
+type Filter interface {
+	Name() string
+	Find(comment string) []Match
+}
+
+// Filter with action taken when it matches.
+type Rule struct {
+	Filter Filter
+	Action Action
+}
+
+// Why a comment was masked, sent to moderation or rejected.
+type Reason struct {
+	Filter string `json:"filter"`
+	Action Action `json:"action"`
+	Reason string `json:"reason"`
+}
+
+type Result struct {
+	// Comment with masked matches.
+	Comment string
+	// The most severe action of all matches.
+	Action  Action
+	Reasons []Reason
+}
+
+// Runs comments through all rules.
+type Pipeline struct {
+	rules []Rule
+}
+
+func NewPipeline(rules ...Rule) *Pipeline {
+	return &Pipeline{rules: rules}
+}
+
+// Creates pipeline with filters and actions from configuration. Rules with action
+// none are left out.
+func New(config config.Config) (*Pipeline, error) {
+	wordlists, err := LoadWordlists(config.ModerationWordlistDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []Rule
+	add := func(filter Filter, action string) {
+		if Action(action) != ActionNone {
+			rules = append(rules, Rule{Filter: filter, Action: Action(action)})
+		}
+	}
+
+	for _, wordlist := range wordlists {
+		add(wordlist, config.ModerationProfanityAction)
+	}
+	add(Links{}, config.ModerationLinkAction)
+	add(PhoneNumbers{}, config.ModerationPhoneAction)
+	add(RepeatedCharacters{Max: config.ModerationMaxRepeatedChars}, config.ModerationRepeatedCharsAction)
+	if len(config.ModerationBlocklist) > 0 {
+		add(NewBlocklist(config.ModerationBlocklist), config.ModerationBlocklistAction)
+	}
+
+	return NewPipeline(rules...), nil
+}
+
+// Checks comment with every rule and masks matches of rules with mask action.
+func (p *Pipeline) Check(comment string) Result {
+	result := Result{Comment: comment, Action: ActionNone, Reasons: []Reason{}}
+
+	var masked []Match
+	for _, rule := range p.rules {
+		for _, match := range rule.Filter.Find(comment) {
+			result.Reasons = append(result.Reasons, Reason{
+				Filter: rule.Filter.Name(),
+				Action: rule.Action,
+				Reason: match.Reason,
+			})
+
+			if severity[rule.Action] > severity[result.Action] {
+				result.Action = rule.Action
+			}
+			if rule.Action == ActionMask {
+				masked = append(masked, match)
+			}
+		}
+	}
+
+	result.Comment = mask(comment, masked)
+	return result
+}
+
+// Replaces every character of matches with an asterisk. Matches may overlap.
+func mask(comment string, matches []Match) string {
+	if len(matches) == 0 {
+		return comment
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Start < matches[j].Start
+	})
+
+	var b strings.Builder
+	last := 0
+	for _, match := range matches {
+		if match.End <= last {
+			continue
+		}
+		if match.Start > last {
+			b.WriteString(comment[last:match.Start])
+		} else {
+			match.Start = last
+		}
+
+		b.WriteString(strings.Repeat("*", utf8.RuneCountInString(comment[match.Start:match.End])))
+		last = match.End
+	}
+	b.WriteString(comment[last:])
+
+	return b.String()
+}
+
+// Describes all reasons in a single line.
+func (r Result) String() string {
+	reasons := make([]string, len(r.Reasons))
+	for i, reason := range r.Reasons {
+		reasons[i] = fmt.Sprintf("%s: %s", reason.Filter, reason.Reason)
+	}
+
+	return strings.Join(reasons, ", ")
+}
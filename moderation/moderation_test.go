@@ -0,0 +1,143 @@
+package moderation
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"rating-service/config"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineAllowsCleanComment(t *testing.T) {
+	pipeline, err := New(config.Default())
+	require.NoError(t, err)
+
+	result := pipeline.Check("Hitro polnjenje, priporočam! Kurir je pustil paket zraven.")
+	require.Equal(t, ActionNone, result.Action)
+	require.Equal(t, "Hitro polnjenje, priporočam! Kurir je pustil paket zraven.", result.Comment)
+	require.Empty(t, result.Reasons)
+}
+
+func TestPipelineMasksProfanity(t *testing.T) {
+	pipeline, err := New(config.Default())
+	require.NoError(t, err)
+
+	result := pipeline.Check("Shitty charger, pizdarija spet ne dela.")
+	require.Equal(t, ActionMask, result.Action)
+	require.Equal(t, "****** charger, ********* spet ne dela.", result.Comment)
+	require.Equal(t, []Reason{
+		{Filter: "profanity", Action: ActionMask, Reason: `word "shitty" (en)`},
+		{Filter: "profanity", Action: ActionMask, Reason: `word "pizdarija" (sl)`},
+	}, result.Reasons)
+}
+
+func TestPipelineMostSevereAction(t *testing.T) {
+	settings := config.Default()
+	settings.ModerationBlocklist = []string{"Cheap Energy"}
+	pipeline, err := New(settings)
+	require.NoError(t, err)
+
+	result := pipeline.Check("Call 041 123 456 or visit www.example.com!!!!!!")
+	require.Equal(t, ActionModerate, result.Action)
+	require.Equal(t, "Call *********** or visit www.example.com!!!!!!", result.Comment)
+	require.Len(t, result.Reasons, 3)
+	require.Equal(t, "link", result.Reasons[0].Filter)
+	require.Equal(t, "phone", result.Reasons[1].Filter)
+	require.Equal(t, "repeated_characters", result.Reasons[2].Filter)
+
+	result = pipeline.Check("Buy CHEAP energy now")
+	require.Equal(t, ActionReject, result.Action)
+	require.Equal(t, `blocklist: phrase "cheap energy"`, result.String())
+}
+
+func TestPipelineAllowsInnocentWords(t *testing.T) {
+	pipeline, err := New(config.Default())
+	require.NoError(t, err)
+
+	// Words that start with a profanity aren't masked.
+	for _, comment := range []string{
+		"Parked next to a prickly bush.",
+		"Shitake soup in the cafe near Scunthorpe.",
+		"Dickens would write a novel about this queue.",
+		"Twattle aside, charging was quick.",
+		"Prasečji zrezek je dober, kretenizem pa je v slovarju.",
+	} {
+		result := pipeline.Check(comment)
+		require.Equal(t, ActionNone, result.Action, comment)
+		require.Equal(t, comment, result.Comment)
+	}
+
+	result := pipeline.Check("Prick, this shit fucking fails.")
+	require.Equal(t, "*****, this **** ******* fails.", result.Comment)
+}
+
+func TestPipelineSkipsDisabledRules(t *testing.T) {
+	settings := config.Default()
+	settings.ModerationProfanityAction = "none"
+	pipeline, err := New(settings)
+	require.NoError(t, err)
+
+	result := pipeline.Check("This is crap.")
+	require.Equal(t, ActionNone, result.Action)
+}
+
+func TestLoadWordlists(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "en.txt"), []byte("# Custom list\nrubbish\nawful*\n"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "hr.txt"), []byte("smeće\n"), 0600))
+
+	wordlists, err := LoadWordlists(dir)
+	require.NoError(t, err)
+	require.Len(t, wordlists, 3)
+
+	// Custom list replaces embedded list of the same language.
+	require.Equal(t, "en", wordlists[0].Language)
+	require.Len(t, wordlists[0].Find("Rubbish, awfully slow, shit."), 2)
+	require.Equal(t, "hr", wordlists[1].Language)
+	require.Len(t, wordlists[1].Find("Smeće od punjača."), 1)
+	require.Equal(t, "sl", wordlists[2].Language)
+
+	_, err = LoadWordlists(filepath.Join(dir, "missing"))
+	require.Error(t, err)
+}
+
+func TestRepeatedCharacters(t *testing.T) {
+	filter := RepeatedCharacters{Max: 3}
+	require.Empty(t, filter.Find("Good!!! Sooo good"))
+
+	matches := filter.Find("Noooooo!!!!")
+	require.Equal(t, []Match{
+		{Start: 1, End: 7, Reason: `'o' repeated 6 times`},
+		{Start: 7, End: 11, Reason: `'!' repeated 4 times`},
+	}, matches)
+}
+
+func TestPhoneNumbers(t *testing.T) {
+	filter := PhoneNumbers{}
+	for _, comment := range []string{
+		"Call 041 123 456",
+		"Call +386 41 123 456 today",
+		"Call 01/234-5678",
+		"Call +38641123456.",
+		"Call 041123456",
+	} {
+		require.Len(t, filter.Find(comment), 1, comment)
+	}
+
+	// Dates, amounts and reference numbers aren't phone numbers.
+	for _, comment := range []string{
+		"charged on 2021-12-01",
+		"charged on 01.12.2021 at 12:30",
+		"failed 12345678 times",
+		"Ref 2021/12/01 12:30",
+		"Paid 1 234 567,89 EUR",
+		"Invoice 2021-000123-45",
+		"Charged 20.5 kWh in 45 min",
+	} {
+		require.Empty(t, filter.Find(comment), comment)
+	}
+
+	matches := filter.Find("Call 041 123 456 for 5 stars")
+	require.Equal(t, []Match{{Start: 5, End: 16, Reason: `phone number "041 123 456"`}}, matches)
+}
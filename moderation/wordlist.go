@@ -0,0 +1,71 @@
+package moderation
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed wordlist/*.txt
+var wordlistFiles embed.FS
+
+// Loads embedded profanity lists. Files <language>.txt in given directory replace
+// embedded list of the same language or add a new language.
+func LoadWordlists(dir string) ([]Wordlist, error) {
+	files, err := readWordlists(wordlistFiles, "wordlist")
+	if err != nil {
+		return nil, err
+	}
+
+	if dir != "" {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("failed to read wordlists: %w", err)
+		}
+
+		custom, err := readWordlists(os.DirFS(dir), ".")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read wordlists: %w", err)
+		}
+		for language, words := range custom {
+			files[language] = words
+		}
+	}
+
+	languages := make([]string, 0, len(files))
+	for language := range files {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	wordlists := make([]Wordlist, len(languages))
+	for i, language := range languages {
+		wordlists[i] = NewWordlist(language, files[language])
+	}
+
+	return wordlists, nil
+}
+
+// Reads words of *.txt files in directory by language.
+func readWordlists(fsys fs.FS, dir string) (map[string][]string, error) {
+	names, err := fs.Glob(fsys, path.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+
+	wordlists := make(map[string][]string)
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		language := strings.TrimSuffix(path.Base(name), ".txt")
+		wordlists[language] = strings.Split(string(data), "\n")
+	}
+
+	return wordlists, nil
+}
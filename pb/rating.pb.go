@@ -0,0 +1,376 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.19.1
+// source: rating.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Rating struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RatingId  int64                  `protobuf:"varint,1,opt,name=rating_id,json=ratingId,proto3" json:"rating_id,omitempty"`
+	StationId int64                  `protobuf:"varint,2,opt,name=station_id,json=stationId,proto3" json:"station_id,omitempty"`
+	UserId    int64                  `protobuf:"varint,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Rating    int64                  `protobuf:"varint,4,opt,name=rating,proto3" json:"rating,omitempty"`
+	Comment   string                 `protobuf:"bytes,5,opt,name=comment,proto3" json:"comment,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Version   int64                  `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"`
+}
+
+func (x *Rating) Reset() {
+	*x = Rating{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rating_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Rating) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rating) ProtoMessage() {}
+
+func (x *Rating) ProtoReflect() protoreflect.Message {
+	mi := &file_rating_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rating.ProtoReflect.Descriptor instead.
+func (*Rating) Descriptor() ([]byte, []int) {
+	return file_rating_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Rating) GetRatingId() int64 {
+	if x != nil {
+		return x.RatingId
+	}
+	return 0
+}
+
+func (x *Rating) GetStationId() int64 {
+	if x != nil {
+		return x.StationId
+	}
+	return 0
+}
+
+func (x *Rating) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Rating) GetRating() int64 {
+	if x != nil {
+		return x.Rating
+	}
+	return 0
+}
+
+func (x *Rating) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+func (x *Rating) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Rating) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type StationSummary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StationId     int64   `protobuf:"varint,1,opt,name=station_id,json=stationId,proto3" json:"station_id,omitempty"`
+	RatingCount   int64   `protobuf:"varint,2,opt,name=rating_count,json=ratingCount,proto3" json:"rating_count,omitempty"`
+	AverageRating float64 `protobuf:"fixed64,3,opt,name=average_rating,json=averageRating,proto3" json:"average_rating,omitempty"`
+	OneStar       int64   `protobuf:"varint,4,opt,name=one_star,json=oneStar,proto3" json:"one_star,omitempty"`
+	TwoStar       int64   `protobuf:"varint,5,opt,name=two_star,json=twoStar,proto3" json:"two_star,omitempty"`
+	ThreeStar     int64   `protobuf:"varint,6,opt,name=three_star,json=threeStar,proto3" json:"three_star,omitempty"`
+	FourStar      int64   `protobuf:"varint,7,opt,name=four_star,json=fourStar,proto3" json:"four_star,omitempty"`
+	FiveStar      int64   `protobuf:"varint,8,opt,name=five_star,json=fiveStar,proto3" json:"five_star,omitempty"`
+	// Unset when no comment of the station has a known sentiment.
+	AverageSentiment    *float64 `protobuf:"fixed64,9,opt,name=average_sentiment,json=averageSentiment,proto3,oneof" json:"average_sentiment,omitempty"`
+	SentimentMismatches int64    `protobuf:"varint,10,opt,name=sentiment_mismatches,json=sentimentMismatches,proto3" json:"sentiment_mismatches,omitempty"`
+	// Ratings since an open anomaly of the station are left out.
+	Frozen bool `protobuf:"varint,11,opt,name=frozen,proto3" json:"frozen,omitempty"`
+}
+
+func (x *StationSummary) Reset() {
+	*x = StationSummary{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rating_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StationSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StationSummary) ProtoMessage() {}
+
+func (x *StationSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_rating_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StationSummary.ProtoReflect.Descriptor instead.
+func (*StationSummary) Descriptor() ([]byte, []int) {
+	return file_rating_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StationSummary) GetStationId() int64 {
+	if x != nil {
+		return x.StationId
+	}
+	return 0
+}
+
+func (x *StationSummary) GetRatingCount() int64 {
+	if x != nil {
+		return x.RatingCount
+	}
+	return 0
+}
+
+func (x *StationSummary) GetAverageRating() float64 {
+	if x != nil {
+		return x.AverageRating
+	}
+	return 0
+}
+
+func (x *StationSummary) GetOneStar() int64 {
+	if x != nil {
+		return x.OneStar
+	}
+	return 0
+}
+
+func (x *StationSummary) GetTwoStar() int64 {
+	if x != nil {
+		return x.TwoStar
+	}
+	return 0
+}
+
+func (x *StationSummary) GetThreeStar() int64 {
+	if x != nil {
+		return x.ThreeStar
+	}
+	return 0
+}
+
+func (x *StationSummary) GetFourStar() int64 {
+	if x != nil {
+		return x.FourStar
+	}
+	return 0
+}
+
+func (x *StationSummary) GetFiveStar() int64 {
+	if x != nil {
+		return x.FiveStar
+	}
+	return 0
+}
+
+func (x *StationSummary) GetAverageSentiment() float64 {
+	if x != nil && x.AverageSentiment != nil {
+		return *x.AverageSentiment
+	}
+	return 0
+}
+
+func (x *StationSummary) GetSentimentMismatches() int64 {
+	if x != nil {
+		return x.SentimentMismatches
+	}
+	return 0
+}
+
+func (x *StationSummary) GetFrozen() bool {
+	if x != nil {
+		return x.Frozen
+	}
+	return false
+}
+
+var File_rating_proto protoreflect.FileDescriptor
+
+var file_rating_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02,
+	0x70, 0x62, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0xe4, 0x01, 0x0a, 0x06, 0x52, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x1b,
+	0x0a, 0x09, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x08, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x73,
+	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x09, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73,
+	0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x75, 0x73, 0x65,
+	0x72, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x06, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x63,
+	0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f,
+	0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64,
+	0x5f, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74,
+	0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x9b, 0x03, 0x0a, 0x0e, 0x53,
+	0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x1d, 0x0a,
+	0x0a, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c,
+	0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0b, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12,
+	0x25, 0x0a, 0x0e, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x72, 0x61, 0x74, 0x69, 0x6e,
+	0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65,
+	0x52, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x19, 0x0a, 0x08, 0x6f, 0x6e, 0x65, 0x5f, 0x73, 0x74,
+	0x61, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x6f, 0x6e, 0x65, 0x53, 0x74, 0x61,
+	0x72, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x77, 0x6f, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x07, 0x74, 0x77, 0x6f, 0x53, 0x74, 0x61, 0x72, 0x12, 0x1d, 0x0a, 0x0a,
+	0x74, 0x68, 0x72, 0x65, 0x65, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x09, 0x74, 0x68, 0x72, 0x65, 0x65, 0x53, 0x74, 0x61, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x66,
+	0x6f, 0x75, 0x72, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08,
+	0x66, 0x6f, 0x75, 0x72, 0x53, 0x74, 0x61, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x76, 0x65,
+	0x5f, 0x73, 0x74, 0x61, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x66, 0x69, 0x76,
+	0x65, 0x53, 0x74, 0x61, 0x72, 0x12, 0x30, 0x0a, 0x11, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65,
+	0x5f, 0x73, 0x65, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01,
+	0x48, 0x00, 0x52, 0x10, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x53, 0x65, 0x6e, 0x74, 0x69,
+	0x6d, 0x65, 0x6e, 0x74, 0x88, 0x01, 0x01, 0x12, 0x31, 0x0a, 0x14, 0x73, 0x65, 0x6e, 0x74, 0x69,
+	0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x6d, 0x69, 0x73, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x13, 0x73, 0x65, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x6e, 0x74,
+	0x4d, 0x69, 0x73, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x72,
+	0x6f, 0x7a, 0x65, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x66, 0x72, 0x6f, 0x7a,
+	0x65, 0x6e, 0x42, 0x14, 0x0a, 0x12, 0x5f, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x73,
+	0x65, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x42, 0x13, 0x5a, 0x11, 0x72, 0x61, 0x74, 0x69,
+	0x6e, 0x67, 0x2d, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rating_proto_rawDescOnce sync.Once
+	file_rating_proto_rawDescData = file_rating_proto_rawDesc
+)
+
+func file_rating_proto_rawDescGZIP() []byte {
+	file_rating_proto_rawDescOnce.Do(func() {
+		file_rating_proto_rawDescData = protoimpl.X.CompressGZIP(file_rating_proto_rawDescData)
+	})
+	return file_rating_proto_rawDescData
+}
+
+var file_rating_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_rating_proto_goTypes = []interface{}{
+	(*Rating)(nil),                // 0: pb.Rating
+	(*StationSummary)(nil),        // 1: pb.StationSummary
+	(*timestamppb.Timestamp)(nil), // 2: google.protobuf.Timestamp
+}
+var file_rating_proto_depIdxs = []int32{
+	2, // 0: pb.Rating.created_at:type_name -> google.protobuf.Timestamp
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_rating_proto_init() }
+func file_rating_proto_init() {
+	if File_rating_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_rating_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Rating); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rating_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StationSummary); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_rating_proto_msgTypes[1].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rating_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rating_proto_goTypes,
+		DependencyIndexes: file_rating_proto_depIdxs,
+		MessageInfos:      file_rating_proto_msgTypes,
+	}.Build()
+	File_rating_proto = out.File
+	file_rating_proto_rawDesc = nil
+	file_rating_proto_goTypes = nil
+	file_rating_proto_depIdxs = nil
+}
@@ -0,0 +1,317 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// RatingServiceClient is the client API for RatingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type RatingServiceClient interface {
+	GetByID(ctx context.Context, in *GetRatingRequest, opts ...grpc.CallOption) (*Rating, error)
+	GetAll(ctx context.Context, in *ListRatingsRequest, opts ...grpc.CallOption) (*ListRatingsResponse, error)
+	Create(ctx context.Context, in *CreateRatingRequest, opts ...grpc.CallOption) (*Rating, error)
+	Update(ctx context.Context, in *UpdateRatingRequest, opts ...grpc.CallOption) (*Rating, error)
+	Delete(ctx context.Context, in *DeleteRatingRequest, opts ...grpc.CallOption) (*DeleteRatingResponse, error)
+	GetAllByStation(ctx context.Context, in *GetStationRequest, opts ...grpc.CallOption) (*ListRatingsResponse, error)
+	GetStationSummary(ctx context.Context, in *GetStationRequest, opts ...grpc.CallOption) (*StationSummary, error)
+}
+
+type ratingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRatingServiceClient(cc grpc.ClientConnInterface) RatingServiceClient {
+	return &ratingServiceClient{cc}
+}
+
+func (c *ratingServiceClient) GetByID(ctx context.Context, in *GetRatingRequest, opts ...grpc.CallOption) (*Rating, error) {
+	out := new(Rating)
+	err := c.cc.Invoke(ctx, "/pb.RatingService/GetByID", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) GetAll(ctx context.Context, in *ListRatingsRequest, opts ...grpc.CallOption) (*ListRatingsResponse, error) {
+	out := new(ListRatingsResponse)
+	err := c.cc.Invoke(ctx, "/pb.RatingService/GetAll", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) Create(ctx context.Context, in *CreateRatingRequest, opts ...grpc.CallOption) (*Rating, error) {
+	out := new(Rating)
+	err := c.cc.Invoke(ctx, "/pb.RatingService/Create", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) Update(ctx context.Context, in *UpdateRatingRequest, opts ...grpc.CallOption) (*Rating, error) {
+	out := new(Rating)
+	err := c.cc.Invoke(ctx, "/pb.RatingService/Update", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) Delete(ctx context.Context, in *DeleteRatingRequest, opts ...grpc.CallOption) (*DeleteRatingResponse, error) {
+	out := new(DeleteRatingResponse)
+	err := c.cc.Invoke(ctx, "/pb.RatingService/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) GetAllByStation(ctx context.Context, in *GetStationRequest, opts ...grpc.CallOption) (*ListRatingsResponse, error) {
+	out := new(ListRatingsResponse)
+	err := c.cc.Invoke(ctx, "/pb.RatingService/GetAllByStation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ratingServiceClient) GetStationSummary(ctx context.Context, in *GetStationRequest, opts ...grpc.CallOption) (*StationSummary, error) {
+	out := new(StationSummary)
+	err := c.cc.Invoke(ctx, "/pb.RatingService/GetStationSummary", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RatingServiceServer is the server API for RatingService service.
+// All implementations must embed UnimplementedRatingServiceServer
+// for forward compatibility
+type RatingServiceServer interface {
+	GetByID(context.Context, *GetRatingRequest) (*Rating, error)
+	GetAll(context.Context, *ListRatingsRequest) (*ListRatingsResponse, error)
+	Create(context.Context, *CreateRatingRequest) (*Rating, error)
+	Update(context.Context, *UpdateRatingRequest) (*Rating, error)
+	Delete(context.Context, *DeleteRatingRequest) (*DeleteRatingResponse, error)
+	GetAllByStation(context.Context, *GetStationRequest) (*ListRatingsResponse, error)
+	GetStationSummary(context.Context, *GetStationRequest) (*StationSummary, error)
+	mustEmbedUnimplementedRatingServiceServer()
+}
+
+// UnimplementedRatingServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedRatingServiceServer struct {
+}
+
+func (UnimplementedRatingServiceServer) GetByID(context.Context, *GetRatingRequest) (*Rating, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetByID not implemented")
+}
+func (UnimplementedRatingServiceServer) GetAll(context.Context, *ListRatingsRequest) (*ListRatingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAll not implemented")
+}
+func (UnimplementedRatingServiceServer) Create(context.Context, *CreateRatingRequest) (*Rating, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
+}
+func (UnimplementedRatingServiceServer) Update(context.Context, *UpdateRatingRequest) (*Rating, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedRatingServiceServer) Delete(context.Context, *DeleteRatingRequest) (*DeleteRatingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedRatingServiceServer) GetAllByStation(context.Context, *GetStationRequest) (*ListRatingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAllByStation not implemented")
+}
+func (UnimplementedRatingServiceServer) GetStationSummary(context.Context, *GetStationRequest) (*StationSummary, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStationSummary not implemented")
+}
+func (UnimplementedRatingServiceServer) mustEmbedUnimplementedRatingServiceServer() {}
+
+// UnsafeRatingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RatingServiceServer will
+// result in compilation errors.
+type UnsafeRatingServiceServer interface {
+	mustEmbedUnimplementedRatingServiceServer()
+}
+
+func RegisterRatingServiceServer(s grpc.ServiceRegistrar, srv RatingServiceServer) {
+	s.RegisterService(&RatingService_ServiceDesc, srv)
+}
+
+func _RatingService_GetByID_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRatingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).GetByID(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.RatingService/GetByID",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).GetByID(ctx, req.(*GetRatingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_GetAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRatingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).GetAll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.RatingService/GetAll",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).GetAll(ctx, req.(*ListRatingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_Create_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRatingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).Create(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.RatingService/Create",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).Create(ctx, req.(*CreateRatingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRatingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.RatingService/Update",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).Update(ctx, req.(*UpdateRatingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRatingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.RatingService/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).Delete(ctx, req.(*DeleteRatingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_GetAllByStation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).GetAllByStation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.RatingService/GetAllByStation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).GetAllByStation(ctx, req.(*GetStationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RatingService_GetStationSummary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RatingServiceServer).GetStationSummary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.RatingService/GetStationSummary",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RatingServiceServer).GetStationSummary(ctx, req.(*GetStationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RatingService_ServiceDesc is the grpc.ServiceDesc for RatingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RatingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.RatingService",
+	HandlerType: (*RatingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetByID",
+			Handler:    _RatingService_GetByID_Handler,
+		},
+		{
+			MethodName: "GetAll",
+			Handler:    _RatingService_GetAll_Handler,
+		},
+		{
+			MethodName: "Create",
+			Handler:    _RatingService_Create_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _RatingService_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _RatingService_Delete_Handler,
+		},
+		{
+			MethodName: "GetAllByStation",
+			Handler:    _RatingService_GetAllByStation_Handler,
+		},
+		{
+			MethodName: "GetStationSummary",
+			Handler:    _RatingService_GetStationSummary_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "service_rating.proto",
+}
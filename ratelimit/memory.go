@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Interval between removals of full buckets.
+const sweepInterval = time.Minute
+
+// Keeps buckets in memory of a single replica.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	burst   float64
+	rate    float64
+	updated time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (m *Memory) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	return m.take(key, limit, 1), nil
+}
+
+func (m *Memory) Peek(ctx context.Context, key string, limit Limit) (Result, error) {
+	return m.take(key, limit, 0), nil
+}
+
+// Takes cost tokens from bucket when it has at least one.
+func (m *Memory) take(key string, limit Limit, cost float64) Result {
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.lastSweep) > sweepInterval {
+		m.sweep(now)
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		m.buckets[key] = b
+	}
+
+	b.burst = float64(limit.Burst)
+	b.rate = limit.Rate
+	b.refill(now)
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens -= cost
+	}
+
+	return newResult(limit, b.tokens, allowed)
+}
+
+func (b *bucket) refill(now time.Time) {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.updated).Seconds()*b.rate)
+	b.updated = now
+}
+
+// Removes buckets that are full again, they are equal to new buckets.
+func (m *Memory) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		b.refill(now)
+		if b.tokens >= b.burst {
+			delete(m.buckets, key)
+		}
+	}
+
+	m.lastSweep = now
+}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryTake(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemory()
+	limit := Limit{Rate: 1, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		result, err := store.Take(ctx, "client", limit)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, i, result.Remaining)
+		require.Zero(t, result.RetryAfter)
+	}
+
+	// Bucket is empty, next token is added within a second.
+	result, err := store.Take(ctx, "client", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Zero(t, result.Remaining)
+	require.Greater(t, result.RetryAfter, time.Duration(0))
+	require.LessOrEqual(t, result.RetryAfter, time.Second)
+	require.LessOrEqual(t, result.Reset, 3*time.Second)
+
+	// Other clients have their own buckets.
+	result, err = store.Take(ctx, "other", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+}
+
+func TestMemoryRefill(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemory()
+	limit := Limit{Rate: 100, Burst: 1}
+
+	result, err := store.Take(ctx, "client", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	result, err = store.Take(ctx, "client", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+
+	time.Sleep(20 * time.Millisecond)
+
+	result, err = store.Take(ctx, "client", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+}
+
+func TestMemoryPeek(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemory()
+	limit := Limit{Rate: 1, Burst: 1}
+
+	// Peeking doesn't take a token.
+	for i := 0; i < 2; i++ {
+		result, err := store.Peek(ctx, "client", limit)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 1, result.Remaining)
+	}
+
+	result, err := store.Take(ctx, "client", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	result, err = store.Peek(ctx, "client", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"rating-service/config"
+	"time"
+)
+
+const (
+	StoreMemory = "memory"
+	StoreRedis  = "redis"
+	StoreNone   = "none"
+)
+
+// Token bucket that refills with Rate tokens per second up to Burst tokens.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+type Result struct {
+	Allowed bool
+	// Number of requests that can be made immediately.
+	Remaining int
+	// Time until the next request is allowed.
+	RetryAfter time.Duration
+	// Time until the bucket is full again.
+	Reset time.Duration
+}
+
+// Keeps token buckets of clients.
+type Store interface {
+	// Takes a token from bucket with given key.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+	// Returns whether a token could be taken from bucket with given key, without
+	// taking it.
+	Peek(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Creates store selected in configuration. Buckets are kept in memory by default.
+func New(config config.Config) (Store, error) {
+	switch config.RateLimitStore {
+	case "", StoreMemory:
+		return NewMemory(), nil
+	case StoreRedis:
+		return NewRedis(config.RedisAddress, config.RedisPassword, config.RedisDB), nil
+	case StoreNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", config.RateLimitStore)
+	}
+}
+
+// Builds result from number of tokens left in the bucket.
+func newResult(limit Limit, tokens float64, allowed bool) Result {
+	result := Result{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(limit.Burst) - tokens) / limit.Rate),
+	}
+
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / limit.Rate)
+	}
+
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
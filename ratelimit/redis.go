@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Refills bucket stored in a hash and takes ARGV[4] tokens from it, 1 or 0 to only
+// check it. Buckets expire once they would be full.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "updated")
+local tokens = tonumber(bucket[1])
+local updated = tonumber(bucket[2])
+if tokens == nil then
+	tokens = burst
+	updated = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - updated) / 1000 * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - cost
+	allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+
+return {allowed, tostring(tokens)}
+`)
+
+// Keeps buckets in Redis, so that limits are shared by all replicas.
+type Redis struct {
+	client *redis.Client
+}
+
+func NewRedis(address, password string, db int) *Redis {
+	client := redis.NewClient(&redis.Options{
+		Addr:     address,
+		Password: password,
+		DB:       db,
+	})
+
+	return &Redis{client: client}
+}
+
+func (r *Redis) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	return r.take(ctx, key, limit, 1)
+}
+
+func (r *Redis) Peek(ctx context.Context, key string, limit Limit) (Result, error) {
+	return r.take(ctx, key, limit, 0)
+}
+
+func (r *Redis) take(ctx context.Context, key string, limit Limit, cost int) (Result, error) {
+	now := time.Now().UnixMilli()
+
+	values, err := takeScript.Run(ctx, r.client, []string{"rating-service:ratelimit:" + key}, limit.Rate, limit.Burst, now, cost).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	allowed, _ := values[0].(int64)
+	tokens, err := strconv.ParseFloat(values[1].(string), 64)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return newResult(limit, tokens, allowed == 1), nil
+}
+
+func (r *Redis) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
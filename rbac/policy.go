@@ -0,0 +1,38 @@
+package rbac
+
+// Permissions required by routes. Routes that are not declared are denied.
+// Routes are declared when server is created and only read afterwards.
+type Policy struct {
+	routes map[string]Permission
+}
+
+func NewPolicy() *Policy {
+	return &Policy{routes: make(map[string]Permission)}
+}
+
+// Declares permission required to call route with given method and path.
+func (p *Policy) Declare(method, path string, permission Permission) {
+	p.routes[method+" "+path] = permission
+}
+
+// Returns permission declared for route with given method and path.
+func (p *Policy) Permission(method, path string) (Permission, bool) {
+	permission, ok := p.routes[method+" "+path]
+	return permission, ok
+}
+
+// Checks if caller with given permissions may call a route. Returns required
+// permission and reason when access is denied.
+func (p *Policy) Check(method, path string, authenticated bool, granted Permissions) (Permission, string, bool) {
+	permission, ok := p.routes[method+" "+path]
+	switch {
+	case !ok:
+		return permission, ReasonUndeclared, false
+	case granted.Has(permission):
+		return permission, "", true
+	case !authenticated:
+		return permission, ReasonUnauthenticated, false
+	default:
+		return permission, ReasonForbidden, false
+	}
+}
@@ -0,0 +1,72 @@
+package rbac
+
+import "rating-service/metrics"
+
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleOperator  Role = "operator"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+type Permission string
+
+const (
+	// Routes that anyone can call, including anonymous callers.
+	Public Permission = "public"
+
+	WriteRatings Permission = "ratings:write"
+	// Lets callers with WriteRatings write ratings of any user, otherwise they
+	// only write their own.
+	WriteAnyRatings Permission = "ratings:write_any"
+	ExportRatings   Permission = "ratings:export"
+	ImportRatings   Permission = "ratings:import"
+	ModerateRatings Permission = "ratings:moderate"
+	PurgeRatings    Permission = "ratings:purge"
+	ManageAPIKeys   Permission = "apikeys:manage"
+)
+
+// Reasons for denied access.
+const (
+	ReasonUnauthenticated = "unauthenticated"
+	ReasonForbidden       = "forbidden"
+	ReasonUndeclared      = "undeclared"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleUser:      {WriteRatings},
+	RoleOperator:  {WriteRatings, WriteAnyRatings, ExportRatings, ImportRatings},
+	RoleModerator: {WriteRatings, WriteAnyRatings, ModerateRatings},
+	RoleAdmin:     {WriteRatings, WriteAnyRatings, ExportRatings, ImportRatings, ModerateRatings, PurgeRatings, ManageAPIKeys},
+}
+
+// Set of granted permissions.
+type Permissions map[Permission]bool
+
+func (p Permissions) Has(permission Permission) bool {
+	return permission == Public || p[permission]
+}
+
+// Returns permissions granted by given roles. Unknown roles grant nothing.
+func PermissionsOf(roles ...Role) Permissions {
+	permissions := Permissions{}
+	for _, role := range roles {
+		for _, permission := range rolePermissions[role] {
+			permissions[permission] = true
+		}
+	}
+
+	return permissions
+}
+
+func ValidRole(role Role) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Counts a denied attempt to call a route.
+func RecordDenied(route string, permission Permission, reason string) {
+	metrics.AuthorizationDenied.WithLabelValues(route, string(permission), reason).Inc()
+}
@@ -0,0 +1,64 @@
+package rbac
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissionsOf(t *testing.T) {
+	user := PermissionsOf(RoleUser)
+	require.True(t, user.Has(Public))
+	require.True(t, user.Has(WriteRatings))
+	require.False(t, user.Has(WriteAnyRatings))
+	require.False(t, user.Has(ExportRatings))
+	require.False(t, user.Has(ModerateRatings))
+
+	require.True(t, PermissionsOf(RoleOperator).Has(ImportRatings))
+	require.False(t, PermissionsOf(RoleOperator).Has(ModerateRatings))
+	require.True(t, PermissionsOf(RoleModerator).Has(ModerateRatings))
+	require.False(t, PermissionsOf(RoleModerator).Has(ManageAPIKeys))
+
+	combined := PermissionsOf(RoleOperator, RoleModerator)
+	require.True(t, combined.Has(ExportRatings))
+	require.True(t, combined.Has(ModerateRatings))
+
+	require.True(t, PermissionsOf(RoleModerator).Has(WriteAnyRatings))
+
+	for _, permission := range []Permission{WriteRatings, WriteAnyRatings, ExportRatings, ImportRatings, ModerateRatings, PurgeRatings, ManageAPIKeys} {
+		require.True(t, PermissionsOf(RoleAdmin).Has(permission), permission)
+	}
+
+	require.Empty(t, PermissionsOf("unknown"))
+}
+
+func TestPolicyCheck(t *testing.T) {
+	policy := NewPolicy()
+	policy.Declare(http.MethodGet, "/v1/ratings/:id", Public)
+	policy.Declare(http.MethodPost, "/v1/ratings/import", ImportRatings)
+
+	_, _, ok := policy.Check(http.MethodGet, "/v1/ratings/:id", false, nil)
+	require.True(t, ok)
+
+	permission, reason, ok := policy.Check(http.MethodPost, "/v1/ratings/import", false, nil)
+	require.False(t, ok)
+	require.Equal(t, ImportRatings, permission)
+	require.Equal(t, ReasonUnauthenticated, reason)
+
+	_, reason, ok = policy.Check(http.MethodPost, "/v1/ratings/import", true, PermissionsOf(RoleUser))
+	require.False(t, ok)
+	require.Equal(t, ReasonForbidden, reason)
+
+	_, _, ok = policy.Check(http.MethodPost, "/v1/ratings/import", true, PermissionsOf(RoleOperator))
+	require.True(t, ok)
+
+	permission, ok = policy.Permission(http.MethodPost, "/v1/ratings/import")
+	require.True(t, ok)
+	require.Equal(t, ImportRatings, permission)
+
+	// Routes without declared permission are denied to everyone.
+	_, reason, ok = policy.Check(http.MethodDelete, "/v1/ratings/:id", true, PermissionsOf(RoleAdmin))
+	require.False(t, ok)
+	require.Equal(t, ReasonUndeclared, reason)
+}
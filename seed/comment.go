@@ -0,0 +1,100 @@
+package seed
+
+import (
+	"math/rand"
+	"strings"
+)
+
+const (
+	Slovenian = "sl"
+	English   = "en"
+
+	// Share of comments in Slovenian.
+	slovenianRate = 0.6
+)
+
+type sentiment int
+
+const (
+	negative sentiment = iota
+	neutral
+	positive
+)
+
+// Comments are built from an opening sentence, a detail and an optional closing sentence.
+type templates struct {
+	openers []string
+	details []string
+	closers []string
+}
+
+var commentTemplates = map[string]map[sentiment]templates{
+	Slovenian: {
+		positive: {
+			openers: []string{"Odlična polnilnica.", "Zelo dobra lokacija.", "Super polnilnica!", "Vse deluje brez težav.", "Dost dobra.", "Nevrjetn dobr! :)", "Priporočam."},
+			details: []string{"Polnjenje je hitro.", "Parkiranje je enostavno.", "V bližini je trgovina in kavarna.", "Plačilo s kartico deluje brez težav.", "Kabel je dovolj dolg.", "Vedno je prosto mesto."},
+			closers: []string{"Še pridem.", "Mogoče še pridem.", "Bil ponovno, še vedno dobra.", "Hvala!"},
+		},
+		neutral: {
+			openers: []string{"Povprečna polnilnica.", "Solidno, nič posebnega.", "V redu za vmesno polnjenje.", "Ok."},
+			details: []string{"Težave pri parkiranju.", "Polnjenje je nekoliko počasno.", "Včasih je zasedena.", "Aplikacija občasno ne deluje.", "Malo težko najti."},
+			closers: []string{"Lahko bi bilo boljše.", "Za silo."},
+		},
+		negative: {
+			openers: []string{"Slaba izkušnja.", "Ne priporočam.", "Polnilnica ne deluje.", "Razočaran."},
+			details: []string{"Kabel je bil pokvarjen.", "Priključek je blokiran.", "Plačilo ni uspelo.", "Parkirno mesto je zasedeno z bencinarji.", "Polnjenje se je večkrat prekinilo."},
+			closers: []string{"Nikoli več.", "Upam, da jo popravijo.", "Izguba časa."},
+		},
+	},
+	English: {
+		positive: {
+			openers: []string{"Great charger.", "Excellent location.", "Works perfectly!", "Very reliable station.", "Love this place."},
+			details: []string{"Charging is fast.", "Parking is easy.", "There is a shop and a cafe nearby.", "Card payment works without problems.", "The cable is long enough.", "Always a free spot."},
+			closers: []string{"Will come again.", "Highly recommended.", "Thanks!"},
+		},
+		neutral: {
+			openers: []string{"Average charger.", "Decent, nothing special.", "Fine for a quick top up.", "Ok."},
+			details: []string{"Parking is a bit tricky.", "Charging is somewhat slow.", "Often occupied.", "The app sometimes fails.", "Hard to find."},
+			closers: []string{"Could be better.", "It does the job."},
+		},
+		negative: {
+			openers: []string{"Bad experience.", "Not recommended.", "Charger never works.", "Disappointed."},
+			details: []string{"The cable was broken.", "The connector is blocked.", "Payment failed.", "Spot was taken by a petrol car.", "Charging stopped several times."},
+			closers: []string{"Never again.", "Hope they fix it.", "Waste of time."},
+		},
+	},
+}
+
+func pickLanguage(rnd *rand.Rand) string {
+	if rnd.Float64() < slovenianRate {
+		return Slovenian
+	}
+
+	return English
+}
+
+func sentimentOf(stars int64) sentiment {
+	switch {
+	case stars <= 2:
+		return negative
+	case stars == 3:
+		return neutral
+	default:
+		return positive
+	}
+}
+
+func comment(rnd *rand.Rand, language string, stars int64) string {
+	t := commentTemplates[language][sentimentOf(stars)]
+
+	parts := []string{pickString(rnd, t.openers), pickString(rnd, t.details)}
+	if rnd.Float64() < 0.3 {
+		parts = append(parts, pickString(rnd, t.closers))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func pickString(rnd *rand.Rand, values []string) string {
+	return values[rnd.Intn(len(values))]
+}
@@ -0,0 +1,192 @@
+package seed
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+const (
+	defaultStations = 50
+	defaultUsers    = 500
+	defaultRatings  = 1000
+	defaultMonths   = 6
+
+	// Share of ratings without a comment.
+	emptyCommentRate = 0.15
+)
+
+// Default end of generated ratings. It is fixed, so that a seed produces the
+// same ratings on any day.
+var defaultEnd = time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type Options struct {
+	// Seed of the generator, same seed and options produce the same ratings.
+	Seed     int64
+	Stations int
+	Users    int
+	Ratings  int
+
+	// Ratings are spread over given number of months before End, with more
+	// ratings in recent months. End defaults to 2022-01-01 (UTC).
+	Months int
+	End    time.Time
+}
+
+// Generated rating.
+type Rating struct {
+	StationID int64
+	UserID    int64
+	Stars     int64
+	Comment   string
+	Language  string
+	CreatedAt time.Time
+}
+
+// Generates realistic ratings: stars are skewed towards positive ratings, some
+// stations are much more popular than others and comments are in Slovenian or English.
+// Generator is not safe for concurrent use.
+type Generator struct {
+	rnd       *rand.Rand
+	options   Options
+	start     time.Time
+	stations  []station
+	popular   *rand.Zipf
+	active    *rand.Zipf
+	generated int
+}
+
+type station struct {
+	id      int64
+	quality quality
+}
+
+func New(options Options) *Generator {
+	if options.Stations <= 0 {
+		options.Stations = defaultStations
+	}
+	if options.Users <= 0 {
+		options.Users = defaultUsers
+	}
+	if options.Ratings <= 0 {
+		options.Ratings = defaultRatings
+	}
+	if options.Months <= 0 {
+		options.Months = defaultMonths
+	}
+	if options.End.IsZero() {
+		options.End = defaultEnd
+	}
+
+	rnd := rand.New(rand.NewSource(options.Seed))
+	g := &Generator{
+		rnd:     rnd,
+		options: options,
+		start:   options.End.AddDate(0, -options.Months, 0),
+
+		// Number of ratings per station and per user follows a power law.
+		popular: rand.NewZipf(rnd, 1.1, 1, uint64(options.Stations-1)),
+		active:  rand.NewZipf(rnd, 1.2, 1, uint64(options.Users-1)),
+	}
+
+	// Shuffle stations, so that popularity doesn't follow IDs.
+	for _, i := range rnd.Perm(options.Stations) {
+		g.stations = append(g.stations, station{
+			id:      int64(i + 1),
+			quality: pickQuality(rnd),
+		})
+	}
+
+	return g
+}
+
+// Returns next rating, or false when all ratings were generated.
+func (g *Generator) Next() (Rating, bool) {
+	if g.generated >= g.options.Ratings {
+		return Rating{}, false
+	}
+	g.generated++
+
+	station := g.stations[g.popular.Uint64()]
+	stars := station.quality.stars(g.rnd)
+	comment, language := g.Comment(stars)
+
+	return Rating{
+		StationID: station.id,
+		UserID:    int64(g.active.Uint64()) + 1,
+		Stars:     stars,
+		Comment:   comment,
+		Language:  language,
+		CreatedAt: g.createdAt(),
+	}, true
+}
+
+// Returns number of stars from the distribution of all stations.
+func (g *Generator) Stars() int64 {
+	return pick(g.rnd, overallStars)
+}
+
+// Returns comment that matches given number of stars and its language. Some comments are empty.
+func (g *Generator) Comment(stars int64) (string, string) {
+	language := pickLanguage(g.rnd)
+	if g.rnd.Float64() < emptyCommentRate {
+		return "", language
+	}
+
+	return comment(g.rnd, language, stars), language
+}
+
+// Returns creation time with density growing towards the end of the range.
+func (g *Generator) createdAt() time.Time {
+	span := g.options.End.Sub(g.start)
+	offset := time.Duration(math.Sqrt(g.rnd.Float64()) * float64(span))
+
+	return g.start.Add(offset).Truncate(time.Second)
+}
+
+// Weights of 1 to 5 stars.
+type distribution [5]float64
+
+// Overall distribution is J-shaped, like on most review sites.
+var overallStars = distribution{0.12, 0.06, 0.10, 0.24, 0.48}
+
+type quality int
+
+const (
+	good quality = iota
+	average
+	bad
+)
+
+var qualityStars = map[quality]distribution{
+	good:    {0.05, 0.03, 0.07, 0.25, 0.60},
+	average: {0.15, 0.10, 0.20, 0.30, 0.25},
+	bad:     {0.45, 0.20, 0.15, 0.10, 0.10},
+}
+
+func pickQuality(rnd *rand.Rand) quality {
+	switch p := rnd.Float64(); {
+	case p < 0.6:
+		return good
+	case p < 0.9:
+		return average
+	default:
+		return bad
+	}
+}
+
+func (q quality) stars(rnd *rand.Rand) int64 {
+	return pick(rnd, qualityStars[q])
+}
+
+func pick(rnd *rand.Rand, weights distribution) int64 {
+	p := rnd.Float64()
+	for i, weight := range weights {
+		if p < weight {
+			return int64(i + 1)
+		}
+		p -= weight
+	}
+
+	return int64(len(weights))
+}
@@ -0,0 +1,126 @@
+package seed
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testEnd = time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+
+func generate(options Options) []Rating {
+	g := New(options)
+
+	var ratings []Rating
+	for {
+		rating, ok := g.Next()
+		if !ok {
+			return ratings
+		}
+		ratings = append(ratings, rating)
+	}
+}
+
+func TestGeneratorIsDeterministic(t *testing.T) {
+	options := Options{Seed: 42, Ratings: 200, End: testEnd}
+
+	require.Equal(t, generate(options), generate(options))
+
+	options.Seed = 43
+	require.NotEqual(t, generate(Options{Seed: 42, Ratings: 200, End: testEnd}), generate(options))
+}
+
+func TestGeneratorRatings(t *testing.T) {
+	options := Options{Seed: 1, Stations: 20, Users: 100, Ratings: 5000, Months: 3, End: testEnd}
+	ratings := generate(options)
+	require.Len(t, ratings, options.Ratings)
+
+	start := testEnd.AddDate(0, -3, 0)
+	stars := make(map[int64]int)
+	stations := make(map[int64]int)
+	languages := make(map[string]int)
+	recent := 0
+
+	for _, rating := range ratings {
+		require.True(t, rating.StationID >= 1 && rating.StationID <= 20)
+		require.True(t, rating.UserID >= 1 && rating.UserID <= 100)
+		require.True(t, rating.Stars >= 1 && rating.Stars <= 5)
+		require.LessOrEqual(t, utf8.RuneCountInString(rating.Comment), 256)
+		require.False(t, rating.CreatedAt.Before(start))
+		require.True(t, rating.CreatedAt.Before(testEnd))
+
+		stars[rating.Stars]++
+		stations[rating.StationID]++
+		languages[rating.Language]++
+		if rating.CreatedAt.After(testEnd.AddDate(0, 0, -45)) {
+			recent++
+		}
+	}
+
+	// Stars are skewed towards positive ratings.
+	require.Greater(t, stars[5], stars[4])
+	require.Greater(t, stars[4], stars[2])
+	require.Greater(t, stars[1], stars[2])
+
+	// Few stations get most of the ratings.
+	counts := make([]int, 0, len(stations))
+	for _, count := range stations {
+		counts = append(counts, count)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+	require.Greater(t, counts[0], 5*counts[len(counts)/2])
+
+	// Both languages are used, and recent months have more ratings.
+	require.Greater(t, languages[Slovenian], languages[English])
+	require.NotZero(t, languages[English])
+	require.Greater(t, recent, len(ratings)/2)
+}
+
+func TestGeneratorSingleStation(t *testing.T) {
+	ratings := generate(Options{Seed: 1, Stations: 1, Users: 1, Ratings: 10, End: testEnd})
+	require.Len(t, ratings, 10)
+	for _, rating := range ratings {
+		require.Equal(t, int64(1), rating.StationID)
+		require.Equal(t, int64(1), rating.UserID)
+	}
+}
+
+func TestComment(t *testing.T) {
+	g := New(Options{Seed: 7})
+	for i := 0; i < 100; i++ {
+		comment, language := g.Comment(1)
+		require.Contains(t, []string{Slovenian, English}, language)
+		if comment == "" {
+			continue
+		}
+
+		// Negative comments come from negative templates.
+		opener := false
+		for _, o := range commentTemplates[language][negative].openers {
+			if len(comment) >= len(o) && comment[:len(o)] == o {
+				opener = true
+			}
+		}
+		require.True(t, opener, comment)
+	}
+}
+
+func TestGeneratorDefaultEnd(t *testing.T) {
+	options := Options{Seed: 42, Ratings: 200}
+
+	// Without End, output doesn't depend on the current time.
+	first, err := json.Marshal(generate(options))
+	require.NoError(t, err)
+	second, err := json.Marshal(generate(options))
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+
+	options.End = defaultEnd
+	explicit, err := json.Marshal(generate(options))
+	require.NoError(t, err)
+	require.Equal(t, first, explicit)
+}
@@ -0,0 +1,172 @@
+package sentiment
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+//go:embed lexicon/*.txt
+var lexiconFiles embed.FS
+
+const (
+	// Marks negators in lexicon files.
+	negatorMark = "not"
+	// Negator flips the first scored word within this many following words.
+	negationScope = 3
+	// Normalizes sum of scores to (-1, 1), e.g. the sum of 2 becomes about 0.67.
+	alpha = 5
+)
+
+var lexicon = func() *Lexicon {
+	l, err := Load()
+	if err != nil {
+		panic(err)
+	}
+	return l
+}()
+
+// Scores of words of all languages.
+type Lexicon struct {
+	words    map[string]float64
+	prefixes []prefix
+	negators map[string]bool
+}
+
+type prefix struct {
+	prefix string
+	score  float64
+}
+
+// Loads embedded lexicons of English and Slovenian.
+func Load() (*Lexicon, error) {
+	names, err := fs.Glob(lexiconFiles, "lexicon/*.txt")
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Lexicon{words: make(map[string]float64), negators: make(map[string]bool)}
+	for _, name := range names {
+		data, err := lexiconFiles.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.add(string(data)); err != nil {
+			return nil, fmt.Errorf("invalid lexicon %s: %w", name, err)
+		}
+	}
+
+	// Longest prefix wins.
+	sort.Slice(l.prefixes, func(i, j int) bool {
+		return len(l.prefixes[i].prefix) > len(l.prefixes[j].prefix)
+	})
+
+	return l, nil
+}
+
+func (l *Lexicon) add(data string) error {
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return fmt.Errorf("line %d: expected word and score, got %q", i+1, line)
+		}
+		word := strings.ToLower(fields[0])
+
+		if fields[1] == negatorMark {
+			l.negators[word] = true
+			continue
+		}
+
+		score, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || score < -3 || score > 3 {
+			return fmt.Errorf("line %d: score must be from -3 to 3, got %q", i+1, fields[1])
+		}
+
+		if strings.HasSuffix(word, "*") {
+			l.prefixes = append(l.prefixes, prefix{prefix: strings.TrimSuffix(word, "*"), score: score})
+		} else {
+			l.words[word] = score
+		}
+	}
+
+	return nil
+}
+
+// Returns score of a word and whether it has one.
+func (l *Lexicon) lookup(word string) (float64, bool) {
+	if score, ok := l.words[word]; ok {
+		return score, true
+	}
+
+	for _, p := range l.prefixes {
+		if strings.HasPrefix(word, p.prefix) {
+			return p.score, true
+		}
+	}
+
+	return 0, false
+}
+
+// Scores text from -1 for very negative to 1 for very positive. Returns false when
+// no word of the text is in the lexicon.
+func (l *Lexicon) Score(text string) (float64, bool) {
+	var sum float64
+	scored := false
+	negated := 0
+
+	for _, word := range words(text) {
+		if l.negators[word] {
+			negated = negationScope
+			continue
+		}
+
+		score, ok := l.lookup(word)
+		if !ok {
+			if negated > 0 {
+				negated--
+			}
+			continue
+		}
+
+		if negated > 0 {
+			score = -score
+			negated = 0
+		}
+		sum += score
+		scored = true
+	}
+
+	if !scored {
+		return 0, false
+	}
+
+	return sum / math.Sqrt(sum*sum+alpha), true
+}
+
+// Scores text with embedded lexicons.
+func Score(text string) (float64, bool) {
+	return lexicon.Score(text)
+}
+
+// Splits lower cased text into words. Apostrophes are dropped, so that "don't"
+// becomes "dont".
+func words(text string) []string {
+	return strings.FieldsFunc(strings.Map(func(r rune) rune {
+		if r == '\'' || r == '’' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
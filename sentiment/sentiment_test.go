@@ -0,0 +1,61 @@
+package sentiment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScore(t *testing.T) {
+	positive := []string{
+		"Great charger. Charging is fast.",
+		"Card payment works without problems.",
+		"Odlična polnilnica. Plačilo s kartico deluje brez težav.",
+		"Nevrjetn dobr! :)",
+	}
+	for _, comment := range positive {
+		score, ok := Score(comment)
+		require.True(t, ok, comment)
+		require.Greater(t, score, 0.3, comment)
+	}
+
+	negative := []string{
+		"Charger never works.",
+		"Bad experience. The cable was broken. Waste of time.",
+		"Doesn't work, don't recommend.",
+		"Polnilnica ne deluje. Plačilo ni uspelo.",
+		"Razočaran. Kabel je bil pokvarjen.",
+	}
+	for _, comment := range negative {
+		score, ok := Score(comment)
+		require.True(t, ok, comment)
+		require.Less(t, score, -0.3, comment)
+	}
+}
+
+func TestScoreRange(t *testing.T) {
+	score, ok := Score("Amazing, excellent, perfect, love it, best charger, superb!")
+	require.True(t, ok)
+	require.Greater(t, score, 0.9)
+	require.Less(t, score, 1.0)
+
+	// Comments without known words are not scored.
+	_, ok = Score("Station 42 at the mall.")
+	require.False(t, ok)
+	_, ok = Score("")
+	require.False(t, ok)
+}
+
+func TestLexicon(t *testing.T) {
+	l := &Lexicon{words: map[string]float64{}, negators: map[string]bool{}}
+	require.NoError(t, l.add("# comment\n\nnot not\ngood 2\nodličn* 3\n"))
+	require.True(t, l.negators["not"])
+
+	score, ok := l.lookup("odlična")
+	require.True(t, ok)
+	require.Equal(t, 3.0, score)
+
+	require.Error(t, l.add("good"))
+	require.Error(t, l.add("good 5"))
+	require.Error(t, l.add("good great"))
+}
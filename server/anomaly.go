@@ -0,0 +1,73 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"rating-service/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+type listAnomaliesRequest struct {
+	Open   bool  `form:"open"`
+	Offset int32 `form:"offset"`
+	Limit  int32 `form:"limit" binding:"required,min=1,max=100"`
+}
+
+type getAnomalyRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) ListAnomalies(ctx *gin.Context) {
+
+	// Check if request has parameters offset and limit for pagination.
+	var req listAnomaliesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	arg := db.ListAnomalyParam{
+		Offset: req.Offset,
+		Limit:  req.Limit,
+		Open:   req.Open,
+	}
+
+	// Execute query.
+	result, err := server.store.ListAnomalies(ctx.Request.Context(), arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) ResolveAnomaly(ctx *gin.Context) {
+
+	// Check if request has ID field in URI.
+	var req getAnomalyRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	// Execute query.
+	result, err := server.store.ResolveAnomaly(ctx.Request.Context(), req.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "anomaly not found"})
+		ctx.Abort()
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,136 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"rating-service/auth"
+	"rating-service/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+type getAPIKeyRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type createAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required,max=64"`
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+}
+
+func (server *Server) CreateAPIKey(ctx *gin.Context) {
+
+	// Check if request has all required fields in json body.
+	var req createAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		if !auth.ValidScope(scope) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"message": fmt.Sprintf("unknown scope %q", scope)})
+			ctx.Abort()
+			return
+		}
+	}
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	arg := db.CreateAPIKeyParam{
+		Name:    req.Name,
+		Scopes:  req.Scopes,
+		Prefix:  prefix,
+		KeyHash: hash,
+	}
+
+	// Execute query.
+	result, err := server.store.CreateAPIKey(ctx.Request.Context(), arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, db.IssuedAPIKey{APIKey: result, Key: key})
+}
+
+func (server *Server) ListAPIKeys(ctx *gin.Context) {
+
+	// Execute query.
+	result, err := server.store.ListAPIKeys(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) RotateAPIKey(ctx *gin.Context) {
+
+	// Check if request has ID field in URI.
+	var req getAPIKeyRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	// Execute query.
+	result, err := server.store.RotateAPIKey(ctx.Request.Context(), req.ID, prefix, hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "api key not found or revoked"})
+		ctx.Abort()
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(http.StatusOK, db.IssuedAPIKey{APIKey: result, Key: key})
+}
+
+func (server *Server) RevokeAPIKey(ctx *gin.Context) {
+
+	// Check if request has ID field in URI.
+	var req getAPIKeyRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	// Execute query.
+	_, err := server.store.RevokeAPIKey(ctx.Request.Context(), req.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "api key not found"})
+		ctx.Abort()
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"rating-service/auth"
+	"rating-service/db"
+	"rating-service/rbac"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	apiKeyHeader = "X-API-Key"
+
+	// Context key under which permissions of authenticated caller are stored.
+	permissionsKey = "permissions"
+)
+
+// Middleware that authenticates callers with an API key or user JWT, if present.
+// Requests without credentials continue anonymously.
+func (server *Server) Authenticate(ctx *gin.Context) {
+
+	key, header := ctx.GetHeader(apiKeyHeader), ctx.GetHeader("Authorization")
+	if key == "" && header == "" {
+		ctx.Next()
+		return
+	}
+
+	// Clients that keep sending invalid credentials are stopped before they cost
+	// a lookup of the key.
+	if !server.allowAuthentication(ctx) {
+		return
+	}
+
+	var principal auth.Principal
+	var err error
+
+	if key != "" {
+		principal, err = server.auth.APIKey(ctx.Request.Context(), key)
+	} else {
+		token := strings.TrimPrefix(header, "Bearer ")
+		if token == header {
+			err = auth.ErrInvalidToken
+		} else {
+			principal, err = server.auth.Token(token)
+		}
+	}
+
+	switch {
+	case errors.Is(err, auth.ErrInvalidAPIKey) || errors.Is(err, auth.ErrInvalidToken):
+		server.recordFailedAuthentication(ctx)
+		unauthorized(ctx, err.Error())
+		return
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	ctx.Set(subjectKey, principal.Subject)
+	ctx.Set(permissionsKey, principal.Permissions)
+	ctx.Next()
+}
+
+// Registers route in group together with permission required to call it.
+func (server *Server) handle(group *gin.RouterGroup, method, relativePath string, permission rbac.Permission, handlers ...gin.HandlerFunc) {
+	server.policy.Declare(method, path.Join(group.BasePath(), relativePath), permission)
+	group.Handle(method, relativePath, handlers...)
+}
+
+// Middleware that only lets through callers with permission declared for the route.
+// Routes without declared permission are denied.
+func (server *Server) Authorize(ctx *gin.Context) {
+	route := ctx.FullPath()
+	authenticated := ctx.GetString(subjectKey) != ""
+	granted, _ := ctx.Value(permissionsKey).(rbac.Permissions)
+
+	permission, reason, ok := server.policy.Check(ctx.Request.Method, route, authenticated, granted)
+	if ok {
+		ctx.Next()
+		return
+	}
+
+	rbac.RecordDenied(route, permission, reason)
+
+	switch reason {
+	case rbac.ReasonUnauthenticated:
+		unauthorized(ctx, "authentication required")
+	case rbac.ReasonForbidden:
+		ctx.JSON(http.StatusForbidden, gin.H{"message": fmt.Sprintf("missing permission %s", permission)})
+		ctx.Abort()
+	default:
+		ctx.JSON(http.StatusForbidden, gin.H{"message": "access denied"})
+		ctx.Abort()
+	}
+}
+
+// Returns authenticated caller, without roles.
+func principalOf(ctx *gin.Context) auth.Principal {
+	granted, _ := ctx.Value(permissionsKey).(rbac.Permissions)
+	return auth.Principal{Subject: ctx.GetString(subjectKey), Permissions: granted}
+}
+
+// Checks that caller may write ratings of user with given ID and responds with 403
+// otherwise.
+func authorizeOwner(ctx *gin.Context, userID int64) bool {
+	if principalOf(ctx).MayWriteRatingsOf(userID) {
+		return true
+	}
+
+	rbac.RecordDenied(ctx.FullPath(), rbac.WriteAnyRatings, rbac.ReasonForbidden)
+	ctx.JSON(http.StatusForbidden, gin.H{"message": "ratings of other users can't be written"})
+	ctx.Abort()
+	return false
+}
+
+// Checks that caller may change given version of stored rating. The rating is only
+// read for callers that may write their own ratings only.
+func (server *Server) authorizeRatingOwner(ctx *gin.Context, id, version int64) bool {
+	if principalOf(ctx).Permissions.Has(rbac.WriteAnyRatings) {
+		return true
+	}
+
+	current, err := server.store.GetByID(db.WithPrimary(ctx.Request.Context()), id)
+	if err != nil {
+		writeConditionalError(ctx, err)
+		return false
+	}
+	if !authorizeOwner(ctx, current.User_id) {
+		return false
+	}
+
+	// Owner was checked for the current version, writes of any other fail.
+	if current.Version != version {
+		writeConditionalError(ctx, db.ErrVersionMismatch)
+		return false
+	}
+
+	return true
+}
+
+func unauthorized(ctx *gin.Context, message string) {
+	ctx.Header("WWW-Authenticate", `Bearer realm="rating-service"`)
+	ctx.JSON(http.StatusUnauthorized, gin.H{"message": message})
+	ctx.Abort()
+}
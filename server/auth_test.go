@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"rating-service/rbac"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizeOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := &Server{policy: rbac.NewPolicy()}
+	router := gin.New()
+	v1 := router.Group("v1", func(ctx *gin.Context) {
+		role := rbac.Role(ctx.GetHeader("X-Role"))
+		ctx.Set(subjectKey, "user:21")
+		ctx.Set(permissionsKey, rbac.PermissionsOf(rbac.RoleUser, role))
+	}, server.Authorize)
+	server.handle(v1, http.MethodPost, "/ratings", rbac.WriteRatings, server.Create)
+	server.handle(v1, http.MethodPost, "/owner/:id", rbac.WriteRatings, func(ctx *gin.Context) {
+		userID := int64(21)
+		if ctx.Param("id") != "21" {
+			userID = 22
+		}
+		if authorizeOwner(ctx, userID) {
+			ctx.Status(http.StatusOK)
+		}
+	})
+
+	request := func(path, role, body string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("X-Role", role)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	// Users can't create ratings of other users.
+	require.Equal(t, http.StatusForbidden, request("/v1/ratings", "", `{"station_id":1,"user_id":22,"rating":5}`))
+
+	require.Equal(t, http.StatusOK, request("/v1/owner/21", "", ""))
+	require.Equal(t, http.StatusForbidden, request("/v1/owner/22", "", ""))
+
+	// Moderators write ratings of anyone.
+	require.Equal(t, http.StatusOK, request("/v1/owner/22", string(rbac.RoleModerator), ""))
+}
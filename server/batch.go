@@ -0,0 +1,60 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Maximum number of IDs accepted by batch endpoints.
+const maxBatchSize = 100
+
+// Parses comma separated list of IDs and removes duplicates.
+func parseIDs(value string) ([]int64, error) {
+	parts := strings.Split(value, ",")
+
+	ids := make([]int64, 0, len(parts))
+	seen := make(map[int64]bool, len(parts))
+	for _, part := range parts {
+		id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil || id < 1 {
+			return nil, fmt.Errorf("invalid id %q", part)
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, checkBatchSize(ids)
+}
+
+// Removes duplicates from list of IDs and validates them.
+func uniqueIDs(values []int64) ([]int64, error) {
+	ids := make([]int64, 0, len(values))
+	seen := make(map[int64]bool, len(values))
+	for _, id := range values {
+		if id < 1 {
+			return nil, fmt.Errorf("invalid id %d", id)
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, checkBatchSize(ids)
+}
+
+func checkBatchSize(ids []int64) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("at least one id is required")
+	}
+	if len(ids) > maxBatchSize {
+		return fmt.Errorf("at most %d ids are allowed", maxBatchSize)
+	}
+
+	return nil
+}
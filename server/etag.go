@@ -0,0 +1,97 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Returns strong entity tag of a rating version.
+func versionETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// Returns weak entity tag derived from response content.
+func contentETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// Checks if any entity tag in If-Match or If-None-Match header value matches given tag.
+// Tags are compared weakly, ignoring W/ prefix.
+func matchETag(header, etag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reads version of a rating expected by If-Match header. Writes 428 or 412
+// response and returns false if header is missing or doesn't contain a version.
+// For "*" zero is returned, meaning that any version matches.
+func ifMatchVersion(ctx *gin.Context) (int64, bool) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" {
+		ctx.JSON(http.StatusPreconditionRequired, gin.H{"message": "If-Match header is required"})
+		ctx.Abort()
+		return 0, false
+	}
+
+	if header == "*" {
+		return 0, true
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version < 1 {
+		ctx.JSON(http.StatusPreconditionFailed, gin.H{"message": "If-Match header doesn't match any version of the rating"})
+		ctx.Abort()
+		return 0, false
+	}
+
+	return version, true
+}
+
+// Writes JSON response with given entity tag, or 304 if client already has it.
+func jsonWithETag(ctx *gin.Context, status int, etag string, obj interface{}) {
+	ctx.Header("ETag", etag)
+
+	if match := ctx.GetHeader("If-None-Match"); match != "" && matchETag(match, etag) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	ctx.JSON(status, obj)
+}
+
+// Writes JSON response with entity tag derived from its content, or 304 if client already has it.
+func jsonWithContentETag(ctx *gin.Context, obj interface{}) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	etag := contentETag(data)
+	ctx.Header("ETag", etag)
+
+	if match := ctx.GetHeader("If-None-Match"); match != "" && matchETag(match, etag) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	ctx.Data(http.StatusOK, gin.MIMEJSON+"; charset=utf-8", data)
+}
@@ -1,24 +1,127 @@
 package server
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
+	"rating-service/db"
+	"rating-service/health"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
 )
 
 func (server *Server) Live(ctx *gin.Context) {
-	ctx.JSON(http.StatusOK, gin.H{"status": "UP"})
+	ctx.JSON(http.StatusOK, gin.H{"status": health.StatusUp})
 }
 
+// Reports status of each dependency. Responds with 503 when any of them is down.
 func (server *Server) Ready(ctx *gin.Context) {
+	report := server.health.Run(ctx.Request.Context())
+	if report.Status != health.StatusUp {
+		ctx.JSON(http.StatusServiceUnavailable, report)
+		ctx.Abort()
+		return
+	}
 
-	// Check connection with database.
-	err := server.store.PingDB()
-	if err != nil {
-		ctx.JSON(http.StatusServiceUnavailable, gin.H{"status": "DOWN"})
+	ctx.JSON(http.StatusOK, report)
+}
+
+// Reports whether service has started, that is all dependencies were up at least once.
+func (server *Server) Startup(ctx *gin.Context) {
+	if atomic.LoadInt32(&server.started) == 1 {
+		ctx.JSON(http.StatusOK, gin.H{"status": health.StatusUp})
+		return
+	}
+
+	report := server.health.Run(ctx.Request.Context())
+	if report.Status != health.StatusUp {
+		ctx.JSON(http.StatusServiceUnavailable, report)
 		ctx.Abort()
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"status": "UP"})
+	atomic.StoreInt32(&server.started, 1)
+	ctx.JSON(http.StatusOK, report)
+}
+
+// Registers checks of database and configured cache, rate limit and alert stores.
+func (server *Server) registerHealthChecks() {
+	server.health.Register("database", health.Ping(server.store))
+	server.health.Register("migrations", server.checkMigrations)
+	server.health.Register("pool", server.checkPool)
+	if len(server.store.ReplicaHealth()) > 0 {
+		server.health.Register("replicas", server.checkReplicas)
+	}
+
+	if pinger, ok := server.ratings.Backend().(health.Pinger); ok {
+		server.health.Register("cache", health.Ping(pinger))
+	}
+	if pinger, ok := server.limiter.(health.Pinger); ok {
+		server.health.Register("rate_limit", health.Ping(pinger))
+	}
+	if pinger, ok := server.alerts.(health.Pinger); ok {
+		server.health.Register("alerts", health.Ping(pinger))
+	}
+}
+
+// Checks that all migrations the service expects were applied.
+func (server *Server) checkMigrations(ctx context.Context) (map[string]interface{}, error) {
+	version, dirty, err := server.store.MigrationVersion(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		version, err = 0, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]interface{}{
+		"version":  version,
+		"expected": db.SchemaVersion,
+		"dirty":    dirty,
+	}
+
+	// Newer schema is fine while replicas with older version are still running.
+	switch {
+	case dirty:
+		return details, fmt.Errorf("migration %d failed", version)
+	case version < db.SchemaVersion:
+		return details, fmt.Errorf("schema version %d is behind expected version %d", version, db.SchemaVersion)
+	}
+
+	return details, nil
+}
+
+// Checks that there are free connections in the pool.
+func (server *Server) checkPool(ctx context.Context) (map[string]interface{}, error) {
+	stats := server.store.Stats()
+	details := map[string]interface{}{
+		"open":       stats.OpenConnections,
+		"in_use":     stats.InUse,
+		"idle":       stats.Idle,
+		"max_open":   stats.MaxOpenConnections,
+		"wait_count": stats.WaitCount,
+	}
+
+	if stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections {
+		return details, fmt.Errorf("all %d connections are in use", stats.MaxOpenConnections)
+	}
+
+	return details, nil
+}
+
+// Reports which read replicas are healthy. Reads fall back to primary, so replicas
+// that are down don't make service unavailable.
+func (server *Server) checkReplicas(ctx context.Context) (map[string]interface{}, error) {
+	details := make(map[string]interface{})
+	for name, healthy := range server.store.ReplicaHealth() {
+		details[name] = health.StatusDown
+		if healthy {
+			details[name] = health.StatusUp
+		}
+	}
+
+	return details, nil
 }
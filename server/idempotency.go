@@ -0,0 +1,153 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	defaultIdempotencyTTL     = 24 * time.Hour
+	defaultIdempotencyLease   = time.Minute
+	// How often expired keys are deleted.
+	idempotencyCleanupInterval = time.Hour
+)
+
+// Captures response body, so it can be stored together with idempotency key.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Middleware that makes requests with Idempotency-Key header safe to retry.
+// Successful responses are stored and replayed for retries with the same key and body.
+func (server *Server) Idempotent(ctx *gin.Context) {
+
+	key := ctx.GetHeader(idempotencyKeyHeader)
+	if key == "" {
+		ctx.Next()
+		return
+	}
+
+	if len(key) > maxIdempotencyKeyLength {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "idempotency key is too long"})
+		ctx.Abort()
+		return
+	}
+
+	// Read body, so it can be hashed and still bound by the handler.
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	settings := server.config.Load()
+	ttl, lease := settings.IdempotencyTTL, settings.IdempotencyLease
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	if lease <= 0 {
+		lease = defaultIdempotencyLease
+	}
+
+	// Keys are scoped by caller, so that nobody can replay a response of another caller.
+	subject := ctx.GetString(subjectKey)
+	hash := requestHash(ctx.Request, body)
+	stored, reserved, err := server.store.ReserveIdempotencyKey(ctx.Request.Context(), subject, key, hash, lease)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	if !reserved {
+		switch {
+		case stored.RequestHash != hash:
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"message": "idempotency key was already used with a different request"})
+		case stored.ResponseStatus == 0:
+			ctx.JSON(http.StatusConflict, gin.H{"message": "request with this idempotency key is still in progress"})
+		default:
+			ctx.Header(idempotencyReplayedHeader, "true")
+			ctx.Data(stored.ResponseStatus, gin.MIMEJSON+"; charset=utf-8", stored.ResponseBody)
+		}
+		ctx.Abort()
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+	ctx.Writer = recorder
+
+	ctx.Next()
+
+	// Only successful responses are replayed, failed requests may be retried.
+	status := recorder.Status()
+	if status >= 200 && status < 300 {
+		err = server.store.CompleteIdempotencyKey(ctx.Request.Context(), subject, key, stored.Reservation, status, recorder.body.Bytes(), ttl)
+	} else {
+		err = server.store.ReleaseIdempotencyKey(ctx.Request.Context(), subject, key, stored.Reservation)
+	}
+	if err != nil {
+		ctx.Error(err)
+	}
+}
+
+// Hashes method, path and body of a request. JSON bodies are normalized,
+// so that formatting and order of fields don't matter.
+func requestHash(req *http.Request, body []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err == nil {
+		if normalized, err := json.Marshal(value); err == nil {
+			body = normalized
+		}
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.Path + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Deletes expired idempotency keys every hour until context is done.
+func (server *Server) CleanupIdempotencyKeys(ctx context.Context) {
+	ticker := time.NewTicker(idempotencyCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := server.store.DeleteExpiredIdempotencyKeys(ctx)
+			if err != nil {
+				zap.L().Error("Failed to delete expired idempotency keys", zap.Error(err))
+				continue
+			}
+			zap.L().Debug("Deleted expired idempotency keys", zap.Int64("count", deleted))
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"rating-service/logging"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+
+	// Request IDs from clients that are longer are replaced.
+	maxRequestIDLength = 128
+)
+
+// Middleware that takes request ID from X-Request-ID header or generates one,
+// echoes it in the response and adds it to the request logger together with trace ID.
+func RequestID(ctx *gin.Context) {
+	requestID := ctx.GetHeader(requestIDHeader)
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		requestID = newRequestID()
+	}
+
+	ctx.Header(requestIDHeader, requestID)
+
+	logger := zap.L().With(zap.String("request_id", requestID))
+	if span := trace.SpanContextFromContext(ctx.Request.Context()); span.HasTraceID() {
+		logger = logger.With(zap.String("trace_id", span.TraceID().String()))
+	}
+	ctx.Request = ctx.Request.WithContext(logging.WithContext(ctx.Request.Context(), logger))
+
+	ctx.Next()
+}
+
+// Middleware that logs every request once it is handled. Server errors are
+// logged as errors and client errors as warnings.
+func Logger(ctx *gin.Context) {
+	start := time.Now()
+	ctx.Next()
+
+	status := ctx.Writer.Status()
+	level := zapcore.InfoLevel
+	switch {
+	case status >= http.StatusInternalServerError:
+		level = zapcore.ErrorLevel
+	case status >= http.StatusBadRequest:
+		level = zapcore.WarnLevel
+	}
+
+	logger := logging.FromContext(ctx.Request.Context())
+	entry := logger.Check(level, "Request handled")
+	if entry == nil {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("method", ctx.Request.Method),
+		zap.String("route", ctx.FullPath()),
+		zap.String("path", ctx.Request.URL.Path),
+		zap.Int("status", status),
+		zap.Duration("latency", time.Since(start)),
+		zap.String("client_ip", ctx.ClientIP()),
+		zap.Int("size", ctx.Writer.Size()),
+	}
+	if subject := ctx.GetString(subjectKey); subject != "" {
+		fields = append(fields, zap.String("user_id", subject))
+	}
+	if len(ctx.Errors) > 0 {
+		fields = append(fields, zap.String("errors", ctx.Errors.String()))
+	}
+
+	entry.Write(fields...)
+}
+
+// Middleware that recovers from panics in handlers, logs them and responds with 500.
+func Recovery(ctx *gin.Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			logging.FromContext(ctx.Request.Context()).Error("Recovered from panic",
+				zap.Any("error", err),
+				zap.ByteString("stack", debug.Stack()),
+			)
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+		}
+	}()
+
+	ctx.Next()
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(id)
+}
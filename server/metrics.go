@@ -0,0 +1,28 @@
+package server
+
+import (
+	"rating-service/metrics"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware that records duration and size of requests and responses.
+func Metrics(ctx *gin.Context) {
+	start := time.Now()
+	ctx.Next()
+
+	// Unmatched routes are grouped together to keep number of labels bounded.
+	route := ctx.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+
+	labels := []string{ctx.Request.Method, route, strconv.Itoa(ctx.Writer.Status())}
+	metrics.RequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	if size := ctx.Request.ContentLength; size >= 0 {
+		metrics.RequestSize.WithLabelValues(labels...).Observe(float64(size))
+	}
+	metrics.ResponseSize.WithLabelValues(labels...).Observe(float64(ctx.Writer.Size()))
+}
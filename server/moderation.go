@@ -0,0 +1,72 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"rating-service/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (server *Server) GetPending(ctx *gin.Context) {
+
+	// Check if request has parameters offset and limit for pagination.
+	var req getRatingListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	arg := db.ListRatingParam{
+		Offset: req.Offset,
+		Limit:  req.Limit,
+	}
+
+	// Execute query.
+	result, err := server.store.GetPending(ctx.Request.Context(), arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) Approve(ctx *gin.Context) {
+	server.moderate(ctx, db.StatusPublished)
+}
+
+func (server *Server) Remove(ctx *gin.Context) {
+	server.moderate(ctx, db.StatusRemoved)
+}
+
+// Sets status of rating given in URI.
+func (server *Server) moderate(ctx *gin.Context, status string) {
+
+	// Check if request has ID field in URI.
+	var req getRatingRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	// Execute query.
+	result, err := server.store.Moderate(ctx.Request.Context(), req.ID, status)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "rating not found"})
+		ctx.Abort()
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	ctx.Header("ETag", versionETag(result.Version))
+	ctx.JSON(http.StatusOK, result)
+}
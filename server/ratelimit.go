@@ -0,0 +1,145 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"rating-service/config"
+	"rating-service/ratelimit"
+	"rating-service/rbac"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Context key under which authentication middleware stores identity of the caller.
+const subjectKey = "subject"
+
+var (
+	defaultReadLimit  = ratelimit.Limit{Rate: 20, Burst: 40}
+	defaultWriteLimit = ratelimit.Limit{Rate: 1, Burst: 10}
+)
+
+// Middleware that limits request rate of every client with a token bucket.
+// Reads and writes have separate limits, see isReadRoute.
+func (server *Server) RateLimit(ctx *gin.Context) {
+	if server.limiter == nil {
+		ctx.Next()
+		return
+	}
+
+	// Limits are taken from one snapshot of configuration, which may be reloaded.
+	settings := server.config.Load()
+	kind, limit := "read", readLimit(settings)
+	if !server.isReadRoute(ctx.Request.Method, ctx.FullPath()) {
+		kind, limit = "write", writeLimit(settings)
+	}
+
+	result, err := server.limiter.Take(ctx.Request.Context(), kind+":"+clientKey(ctx), limit)
+	if err != nil {
+		// Don't block clients when limiter store is unavailable.
+		ctx.Error(err)
+		ctx.Next()
+		return
+	}
+
+	ctx.Header("RateLimit-Limit", strconv.Itoa(limit.Burst))
+	ctx.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	ctx.Header("RateLimit-Reset", ceilSeconds(result.Reset))
+
+	if !result.Allowed {
+		tooManyRequests(ctx, result)
+		return
+	}
+
+	ctx.Next()
+}
+
+// Rejects request with invalid credentials before they are checked, when client
+// IP address used up its failed authentication attempts. Attempts are limited
+// like writes. Returns whether the request may continue.
+func (server *Server) allowAuthentication(ctx *gin.Context) bool {
+	if server.limiter == nil {
+		return true
+	}
+
+	result, err := server.limiter.Peek(ctx.Request.Context(), authFailureKey(ctx), writeLimit(server.config.Load()))
+	if err != nil {
+		ctx.Error(err)
+		return true
+	}
+	if !result.Allowed {
+		tooManyRequests(ctx, result)
+		return false
+	}
+
+	return true
+}
+
+// Counts failed authentication attempt against client IP address.
+func (server *Server) recordFailedAuthentication(ctx *gin.Context) {
+	if server.limiter == nil {
+		return
+	}
+
+	if _, err := server.limiter.Take(ctx.Request.Context(), authFailureKey(ctx), writeLimit(server.config.Load())); err != nil {
+		ctx.Error(err)
+	}
+}
+
+// Identifies bucket of failed authentication attempts, callers are not known yet.
+func authFailureKey(ctx *gin.Context) string {
+	return "auth:ip:" + ctx.ClientIP()
+}
+
+func tooManyRequests(ctx *gin.Context, result ratelimit.Result) {
+	ctx.Header("Retry-After", ceilSeconds(result.RetryAfter))
+	ctx.JSON(http.StatusTooManyRequests, gin.H{"message": "rate limit exceeded"})
+	ctx.Abort()
+}
+
+// Identifies client by authenticated subject (API key or JWT), or by IP address otherwise.
+func clientKey(ctx *gin.Context) string {
+	if subject := ctx.GetString(subjectKey); subject != "" {
+		return "subject:" + subject
+	}
+
+	return "ip:" + ctx.ClientIP()
+}
+
+func readLimit(settings config.Config) ratelimit.Limit {
+	return limitOrDefault(settings.RateLimitReadRate, settings.RateLimitReadBurst, defaultReadLimit)
+}
+
+func writeLimit(settings config.Config) ratelimit.Limit {
+	return limitOrDefault(settings.RateLimitWriteRate, settings.RateLimitWriteBurst, defaultWriteLimit)
+}
+
+func limitOrDefault(rate float64, burst int, fallback ratelimit.Limit) ratelimit.Limit {
+	if rate <= 0 || burst <= 0 {
+		return fallback
+	}
+
+	return ratelimit.Limit{Rate: rate, Burst: burst}
+}
+
+// Reports whether route only reads, judged by permission it declares. Public
+// routes never write, whatever their method, e.g. POST of a batch of station
+// summaries. Routes that need a permission read when their method is safe, as one
+// permission, e.g. moderation, covers both listing and changing ratings.
+func (server *Server) isReadRoute(method, route string) bool {
+	permission, ok := server.policy.Permission(method, route)
+	if ok && permission == rbac.Public {
+		return true
+	}
+
+	return isReadMethod(method)
+}
+
+func isReadMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
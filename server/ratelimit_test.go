@@ -0,0 +1,92 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"rating-service/auth"
+	"rating-service/config"
+	"rating-service/ratelimit"
+	"rating-service/rbac"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitBucketOfRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	settings := config.Default()
+	settings.RateLimitReadRate, settings.RateLimitReadBurst = 1, 5
+	settings.RateLimitWriteRate, settings.RateLimitWriteBurst = 1, 2
+
+	server := &Server{config: config.NewLive("", settings), limiter: ratelimit.NewMemory(), policy: rbac.NewPolicy()}
+	router := gin.New()
+	v1 := router.Group("v1", server.RateLimit)
+	ok := func(ctx *gin.Context) { ctx.Status(http.StatusOK) }
+	server.handle(v1, http.MethodPost, "/stations/:method", rbac.Public, ok)
+	server.handle(v1, http.MethodPost, "/ratings", rbac.WriteRatings, ok)
+
+	request := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, nil))
+		return recorder
+	}
+
+	// Batch summaries are public reads and use the read bucket.
+	for i := 0; i < 5; i++ {
+		recorder := request("/v1/stations/ratings:batchSummary")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Equal(t, "5", recorder.Header().Get("RateLimit-Limit"))
+	}
+	require.Equal(t, http.StatusTooManyRequests, request("/v1/stations/ratings:batchSummary").Code)
+
+	// Writes have their own bucket.
+	for i := 0; i < 2; i++ {
+		recorder := request("/v1/ratings")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Equal(t, "2", recorder.Header().Get("RateLimit-Limit"))
+	}
+	require.Equal(t, http.StatusTooManyRequests, request("/v1/ratings").Code)
+}
+
+func TestRateLimitFailedAuthentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	settings := config.Default()
+	settings.RateLimitWriteRate, settings.RateLimitWriteBurst = 0.001, 3
+
+	// Store is not set, a lookup of a key would panic.
+	server := &Server{
+		config:  config.NewLive("", settings),
+		limiter: ratelimit.NewMemory(),
+		auth:    auth.NewAuthenticator(nil, "secret"),
+		policy:  rbac.NewPolicy(),
+	}
+	router := gin.New()
+	v1 := router.Group("v1", server.Authenticate, server.RateLimit, server.Authorize)
+	server.handle(v1, http.MethodGet, "/ratings", rbac.Public, func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	request := func(remoteAddr, key string) int {
+		req := httptest.NewRequest(http.MethodGet, "/v1/ratings", nil)
+		req.RemoteAddr = remoteAddr
+		if key != "" {
+			req.Header.Set(apiKeyHeader, key)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusUnauthorized, request("192.0.2.1:1234", "invalid"))
+	}
+
+	// Further attempts are rejected before the key is looked up.
+	require.Equal(t, http.StatusTooManyRequests, request("192.0.2.1:1234", "invalid"))
+	require.Equal(t, http.StatusTooManyRequests, request("192.0.2.1:1234", "rk_prefix_secret"))
+
+	// Requests without credentials and other clients are not affected.
+	require.Equal(t, http.StatusOK, request("192.0.2.1:1234", ""))
+	require.Equal(t, http.StatusUnauthorized, request("192.0.2.2:1234", "invalid"))
+}
@@ -1,10 +1,18 @@
 package server
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
 	"rating-service/db"
+	"rating-service/logging"
+	"rating-service/rbac"
+	"rating-service/transfer"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 type RatingController struct{}
@@ -18,6 +26,32 @@ type getRatingListRequest struct {
 	Limit  int32 `form:"limit" binding:"required,min=1,max=20"`
 }
 
+type getRatingBatchRequest struct {
+	IDs string `form:"ids" binding:"required"`
+}
+
+type importRatingsRequest struct {
+	Format string `form:"format" binding:"required,oneof=csv ndjson"`
+	DryRun bool   `form:"dry_run"`
+}
+
+type exportRatingsRequest struct {
+	Format    string    `form:"format" binding:"required,oneof=csv ndjson parquet"`
+	StationID int64     `form:"station_id" binding:"min=0"`
+	From      time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type searchRatingsRequest struct {
+	Query     string    `form:"q" binding:"required,max=256"`
+	Language  string    `form:"language" binding:"omitempty,oneof=simple english slovenian"`
+	StationID int64     `form:"station_id" binding:"min=0"`
+	From      time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Offset    int32     `form:"offset"`
+	Limit     int32     `form:"limit" binding:"required,min=1,max=20"`
+}
+
 type createRatingRequest struct {
 	Station_id int64  `json:"station_id" db:"station_id"`
 	User_id    int64  `json:"user_id" db:"user_id"`
@@ -32,6 +66,13 @@ type updateRatingRequest struct {
 	Comment    string `json:"comment" db:"comment"`
 }
 
+type patchRatingRequest struct {
+	Station_id *int64  `json:"station_id" db:"station_id"`
+	User_id    *int64  `json:"user_id" db:"user_id"`
+	Rating     *int64  `json:"rating" db:"rating"`
+	Comment    *string `json:"comment" db:"comment"`
+}
+
 func (server *Server) GetByID(ctx *gin.Context) {
 
 	// Check if request has ID field in URI.
@@ -43,18 +84,37 @@ func (server *Server) GetByID(ctx *gin.Context) {
 	}
 
 	// Execute query.
-	result, err := server.store.GetByID(ctx, req.ID)
+	result, err := server.store.GetByID(ctx.Request.Context(), req.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "rating not found"})
+		ctx.Abort()
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
 		ctx.Abort()
 		return
 	}
 
-	ctx.JSON(http.StatusOK, result)
+	// Only moderators see ratings that are not public.
+	granted, _ := ctx.Value(permissionsKey).(rbac.Permissions)
+	if result.Status != db.StatusPublished && !granted.Has(rbac.ModerateRatings) {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "rating not found"})
+		ctx.Abort()
+		return
+	}
+
+	jsonWithETag(ctx, http.StatusOK, versionETag(result.Version), result)
 }
 
 func (server *Server) GetAll(ctx *gin.Context) {
 
+	// Requests with list of IDs are resolved as a batch lookup.
+	if _, ok := ctx.GetQuery("ids"); ok {
+		server.GetByIDs(ctx)
+		return
+	}
+
 	// Check if request has parameters offset and limit for pagination.
 	var req getRatingListRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
@@ -69,14 +129,75 @@ func (server *Server) GetAll(ctx *gin.Context) {
 	}
 
 	// Execute query.
-	result, err := server.store.GetAll(ctx, arg)
+	result, err := server.store.GetAll(ctx.Request.Context(), arg)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
 		ctx.Abort()
 		return
 	}
 
-	ctx.JSON(http.StatusOK, result)
+	jsonWithContentETag(ctx, result)
+}
+
+func (server *Server) Search(ctx *gin.Context) {
+
+	// Check if request has a query, pagination and valid filters.
+	var req searchRatingsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	arg := db.SearchRatingParam{
+		Query:    req.Query,
+		Language: req.Language,
+		Filter: db.RatingFilter{
+			StationID: req.StationID,
+			From:      req.From,
+			To:        req.To,
+		},
+		Offset: req.Offset,
+		Limit:  req.Limit,
+	}
+
+	// Execute query.
+	result, err := server.store.Search(ctx.Request.Context(), arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	jsonWithContentETag(ctx, result)
+}
+
+func (server *Server) GetByIDs(ctx *gin.Context) {
+
+	// Check if request has a list of IDs.
+	var req getRatingBatchRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	ids, err := parseIDs(req.IDs)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		ctx.Abort()
+		return
+	}
+
+	// Execute query.
+	result, err := server.store.GetByIDs(ctx.Request.Context(), ids)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	jsonWithContentETag(ctx, result)
 }
 
 func (server *Server) Create(ctx *gin.Context) {
@@ -89,6 +210,11 @@ func (server *Server) Create(ctx *gin.Context) {
 		return
 	}
 
+	// Users create ratings of their own.
+	if !authorizeOwner(ctx, req.User_id) {
+		return
+	}
+
 	arg := db.CreateRatingParam{
 		Station_id: req.Station_id,
 		User_id:    req.User_id,
@@ -97,16 +223,94 @@ func (server *Server) Create(ctx *gin.Context) {
 	}
 
 	// Execute query.
-	result, err := server.store.Create(ctx, arg)
+	result, err := server.store.Create(ctx.Request.Context(), arg)
+	var rejected *db.RejectedError
+	if errors.As(err, &rejected) {
+		writeRejectedError(ctx, rejected)
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
 		ctx.Abort()
 		return
 	}
 
+	ctx.Header("ETag", versionETag(result.Version))
 	ctx.JSON(http.StatusCreated, result)
 }
 
+func (server *Server) Import(ctx *gin.Context) {
+
+	// Check if request has format of the body.
+	var req importRatingsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	source, err := transfer.NewReader(req.Format, ctx.Request.Body)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		ctx.Abort()
+		return
+	}
+
+	// Execute import.
+	result, err := server.store.Import(ctx.Request.Context(), source, req.DryRun)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) Export(ctx *gin.Context) {
+
+	// Check if request has format and valid filters.
+	var req exportRatingsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	arg := db.RatingFilter{
+		StationID: req.StationID,
+		From:      req.From,
+		To:        req.To,
+	}
+
+	// Headers are sent together with the first row.
+	ctx.Header("Content-Type", transfer.ContentType(req.Format))
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="ratings.%s"`, req.Format))
+
+	writer, err := transfer.NewWriter(req.Format, ctx.Writer)
+	if err == nil {
+		err = server.store.Export(ctx.Request.Context(), arg, writer.Write)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	if err != nil {
+		// Once streaming started the status can't be changed anymore.
+		if ctx.Writer.Written() {
+			logging.FromContext(ctx.Request.Context()).Error("Failed to export ratings", zap.Error(err))
+			ctx.Abort()
+			return
+		}
+
+		ctx.Writer.Header().Del("Content-Type")
+		ctx.Writer.Header().Del("Content-Disposition")
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+}
+
 func (server *Server) Update(ctx *gin.Context) {
 
 	// Check if request has ID field in URI.
@@ -125,24 +329,87 @@ func (server *Server) Update(ctx *gin.Context) {
 		return
 	}
 
+	// Check if client modifies the latest version.
+	version, ok := server.expectedVersion(ctx, reqID.ID)
+	if !ok {
+		return
+	}
+
+	// Users change their own ratings and can't give them to another user.
+	if !server.authorizeRatingOwner(ctx, reqID.ID, version) || !authorizeOwner(ctx, req.User_id) {
+		return
+	}
+
 	arg := db.UpdateRatingParam{
 		Station_id: req.Station_id,
 		User_id:    req.User_id,
 		Rating:     req.Rating,
 		Comment:    req.Comment,
+		Version:    version,
 	}
 
 	// Execute query.
-	result, err := server.store.Update(ctx, arg, reqID.ID)
+	result, err := server.store.Update(ctx.Request.Context(), arg, reqID.ID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
-		ctx.Abort()
+		writeConditionalError(ctx, err)
 		return
 	}
 
+	ctx.Header("ETag", versionETag(result.Version))
 	ctx.JSON(http.StatusCreated, result)
 }
 
+func (server *Server) Patch(ctx *gin.Context) {
+
+	// Check if request has ID field in URI.
+	var reqID getRatingRequest
+	if err := ctx.ShouldBindUri(&reqID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	// Check if json body contains valid fields.
+	var req patchRatingRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	// Check if client modifies the latest version.
+	version, ok := server.expectedVersion(ctx, reqID.ID)
+	if !ok {
+		return
+	}
+
+	// Users change their own ratings and can't give them to another user.
+	if !server.authorizeRatingOwner(ctx, reqID.ID, version) {
+		return
+	}
+	if req.User_id != nil && !authorizeOwner(ctx, *req.User_id) {
+		return
+	}
+
+	arg := db.PatchRatingParam{
+		Station_id: req.Station_id,
+		User_id:    req.User_id,
+		Rating:     req.Rating,
+		Comment:    req.Comment,
+		Version:    version,
+	}
+
+	// Execute query.
+	result, err := server.store.Patch(ctx.Request.Context(), arg, reqID.ID)
+	if err != nil {
+		writeConditionalError(ctx, err)
+		return
+	}
+
+	ctx.Header("ETag", versionETag(result.Version))
+	ctx.JSON(http.StatusOK, result)
+}
+
 func (server *Server) Delete(ctx *gin.Context) {
 
 	// Check if request has ID field in URI.
@@ -153,16 +420,66 @@ func (server *Server) Delete(ctx *gin.Context) {
 		return
 	}
 
+	// Check if client deletes the latest version.
+	version, ok := server.expectedVersion(ctx, req.ID)
+	if !ok {
+		return
+	}
+
+	// Users delete their own ratings.
+	if !server.authorizeRatingOwner(ctx, req.ID, version) {
+		return
+	}
+
 	// Execute query.
-	if err := server.store.Delete(ctx, req.ID); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
-		ctx.Abort()
+	if err := server.store.DeleteVersion(ctx.Request.Context(), req.ID, version); err != nil {
+		writeConditionalError(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusNoContent, nil)
 }
 
+// Returns version of a rating required by If-Match header. For "*" the current version is used.
+func (server *Server) expectedVersion(ctx *gin.Context, id int64) (int64, bool) {
+	version, ok := ifMatchVersion(ctx)
+	if !ok || version > 0 {
+		return version, ok
+	}
+
+	// Replicas may lag behind, so current version is read from primary.
+	current, err := server.store.GetByID(db.WithPrimary(ctx.Request.Context()), id)
+	if err != nil {
+		writeConditionalError(ctx, err)
+		return 0, false
+	}
+
+	return current.Version, true
+}
+
+// Writes response for a failed conditional write.
+func writeConditionalError(ctx *gin.Context, err error) {
+	var rejected *db.RejectedError
+	switch {
+	case errors.As(err, &rejected):
+		writeRejectedError(ctx, rejected)
+		return
+	case errors.Is(err, db.ErrVersionMismatch):
+		ctx.JSON(http.StatusPreconditionFailed, gin.H{"message": "rating was modified by another request"})
+	case errors.Is(err, sql.ErrNoRows):
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "rating not found"})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+	}
+	ctx.Abort()
+}
+
+// Writes response for a comment that content filters rejected.
+func writeRejectedError(ctx *gin.Context, err *db.RejectedError) {
+	ctx.JSON(http.StatusUnprocessableEntity, gin.H{"message": "comment was rejected", "reasons": err.Reasons})
+	ctx.Abort()
+}
+
 func (server *Server) GetAllByStation(ctx *gin.Context) {
 
 	// Check if request has ID field in URI.
@@ -174,12 +491,33 @@ func (server *Server) GetAllByStation(ctx *gin.Context) {
 	}
 
 	// Execute query.
-	result, err := server.store.GetAllByStation(ctx, req.ID)
+	result, err := server.ratings.GetAllByStation(ctx.Request.Context(), req.ID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
 		ctx.Abort()
 		return
 	}
 
-	ctx.JSON(http.StatusOK, result)
+	jsonWithContentETag(ctx, result)
+}
+
+func (server *Server) GetStationSummary(ctx *gin.Context) {
+
+	// Check if request has ID field in URI.
+	var req getRatingRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	// Execute query.
+	result, err := server.ratings.GetStationSummary(ctx.Request.Context(), req.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	jsonWithContentETag(ctx, result)
 }
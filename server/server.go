@@ -1,58 +1,117 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"rating-service/auth"
+	"rating-service/cache"
 	"rating-service/config"
 	"rating-service/db"
+	"rating-service/events"
+	"rating-service/health"
+	servicemetrics "rating-service/metrics"
+	"rating-service/ratelimit"
+	"rating-service/rbac"
+	"rating-service/tracing"
 
 	"rating-service/docs"
 
 	"github.com/gin-gonic/gin"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 type Server struct {
-	config config.Config
-	store  *db.Store
-	router *gin.Engine
+	config  *config.Live
+	store   *db.Store
+	ratings *cache.Ratings
+	alerts  events.Publisher
+	limiter ratelimit.Store
+	auth    *auth.Authenticator
+	policy  *rbac.Policy
+	health  *health.Checker
+	started int32
+	router  *gin.Engine
+	http    *http.Server
 }
 
-func NewServer(config config.Config, store *db.Store) (*Server, error) {
+// Creates server with current configuration. Reloadable settings are read from live
+// configuration on every request.
+func NewServer(live *config.Live, store *db.Store, ratings *cache.Ratings, alerts events.Publisher) (*Server, error) {
+
+	config := live.Load()
 
 	gin.SetMode(config.GinMode)
-	router := gin.Default()
+	router := gin.New()
+	// Client IP is taken from X-Forwarded-For only when the peer is a trusted proxy.
+	if err := router.SetTrustedProxies(config.TrustedProxies); err != nil {
+		return nil, err
+	}
+	router.Use(otelgin.Middleware(tracing.ServiceName), RequestID, Logger, Recovery, Metrics)
+
+	limiter, err := ratelimit.New(config)
+	if err != nil {
+		return nil, err
+	}
 
 	server := &Server{
-		config: config,
-		store:  store,
+		config:  live,
+		store:   store,
+		ratings: ratings,
+		alerts:  alerts,
+		limiter: limiter,
+		auth:    auth.NewAuthenticator(store, config.JWTSecret),
+		policy:  rbac.NewPolicy(),
+		health:  health.NewChecker(),
 	}
+	server.registerHealthChecks()
 
-	// Setup routing for server.
-	v1 := router.Group("v1")
+	// Setup routing for server. Callers are identified before they are rate limited
+	// and every route declares permission it requires.
+	v1 := router.Group("v1", server.Authenticate, server.RateLimit, server.Authorize)
 	{
-		v1.GET("/ratings/:id", server.GetByID)
-		v1.GET("/ratings", server.GetAll)
-		v1.POST("/ratings", server.Create)
-		v1.PUT("/ratings/:id", server.Update)
-		v1.DELETE("/ratings/:id", server.Delete)
-		v1.GET("/ratings/station/:id", server.GetAllByStation)
+		server.handle(v1, http.MethodGet, "/ratings/:id", rbac.Public, server.GetByID)
+		server.handle(v1, http.MethodGet, "/ratings", rbac.Public, server.GetAll)
+		server.handle(v1, http.MethodGet, "/ratings/search", rbac.Public, server.Search)
+		server.handle(v1, http.MethodGet, "/ratings/station/:id", rbac.Public, server.GetAllByStation)
+		server.handle(v1, http.MethodGet, "/ratings/station/:id/summary", rbac.Public, server.GetStationSummary)
+		server.handle(v1, http.MethodPost, "/stations/:method", rbac.Public, server.StationMethod)
+
+		server.handle(v1, http.MethodPost, "/ratings", rbac.WriteRatings, server.Idempotent, server.Create)
+		server.handle(v1, http.MethodPut, "/ratings/:id", rbac.WriteRatings, server.Update)
+		server.handle(v1, http.MethodPatch, "/ratings/:id", rbac.WriteRatings, server.Patch)
+		server.handle(v1, http.MethodDelete, "/ratings/:id", rbac.WriteRatings, server.Delete)
+
+		server.handle(v1, http.MethodGet, "/ratings/export", rbac.ExportRatings, server.Export)
+		server.handle(v1, http.MethodPost, "/ratings/import", rbac.ImportRatings, server.Import)
+
+		server.handle(v1, http.MethodGet, "/moderation/ratings", rbac.ModerateRatings, server.GetPending)
+		server.handle(v1, http.MethodPost, "/moderation/ratings/:id/approve", rbac.ModerateRatings, server.Approve)
+		server.handle(v1, http.MethodPost, "/moderation/ratings/:id/remove", rbac.ModerateRatings, server.Remove)
+
+		server.handle(v1, http.MethodGet, "/admin/anomalies", rbac.ModerateRatings, server.ListAnomalies)
+		server.handle(v1, http.MethodPost, "/admin/anomalies/:id/resolve", rbac.ModerateRatings, server.ResolveAnomaly)
+
+		server.handle(v1, http.MethodGet, "/admin/api-keys", rbac.ManageAPIKeys, server.ListAPIKeys)
+		server.handle(v1, http.MethodPost, "/admin/api-keys", rbac.ManageAPIKeys, server.CreateAPIKey)
+		server.handle(v1, http.MethodPost, "/admin/api-keys/:id/rotate", rbac.ManageAPIKeys, server.RotateAPIKey)
+		server.handle(v1, http.MethodDelete, "/admin/api-keys/:id", rbac.ManageAPIKeys, server.RevokeAPIKey)
 	}
 
 	// Setup health check routes.
-	health := router.Group("health")
+	probes := router.Group("health")
 	{
-		health.GET("/live", server.Live)
-		health.GET("/ready", server.Ready)
+		probes.GET("/live", server.Live)
+		probes.GET("/ready", server.Ready)
+		probes.GET("/startup", server.Startup)
 	}
 
 	// Setup metrics routes.
 	metrics := router.Group("metrics")
 	{
-		metrics.GET("/", func(ctx *gin.Context) {
-			handler := promhttp.Handler()
-			handler.ServeHTTP(ctx.Writer, ctx.Request)
-		})
+		metrics.GET("/", gin.WrapH(servicemetrics.Handler()))
 	}
 
 	// Open api 2.0.
@@ -64,9 +123,21 @@ func NewServer(config config.Config, store *db.Store) (*Server, error) {
 	}
 
 	server.router = router
+	server.http = &http.Server{Handler: router}
 	return server, nil
 }
 
+// Serves requests on address until Shutdown is called.
 func (server *Server) Start(address string) error {
-	return server.router.Run(address)
+	server.http.Addr = address
+	if err := server.http.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// Stops accepting requests and waits for active ones to finish until ctx is done.
+func (server *Server) Shutdown(ctx context.Context) error {
+	return server.http.Shutdown(ctx)
 }
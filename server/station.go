@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type batchStationSummaryRequest struct {
+	StationIDs []int64 `json:"station_ids" binding:"required"`
+}
+
+// Dispatches custom methods on stations collection, e.g. "ratings:batchSummary".
+// Gin treats colon as a start of path parameter, so custom methods can't be registered directly.
+func (server *Server) StationMethod(ctx *gin.Context) {
+	switch ctx.Param("method") {
+	case "ratings:batchSummary":
+		server.BatchStationSummary(ctx)
+	default:
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "unknown method"})
+		ctx.Abort()
+	}
+}
+
+func (server *Server) BatchStationSummary(ctx *gin.Context) {
+
+	// Check if request has all required fields in json body.
+	var req batchStationSummaryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	stationIDs, err := uniqueIDs(req.StationIDs)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		ctx.Abort()
+		return
+	}
+
+	// Execute query.
+	result, err := server.store.GetStationSummaries(ctx.Request.Context(), stationIDs)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"message": err})
+		ctx.Abort()
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
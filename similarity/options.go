@@ -0,0 +1,32 @@
+package similarity
+
+import (
+	"rating-service/config"
+	"rating-service/moderation"
+	"time"
+)
+
+// Settings of duplicate comment detection.
+type Options struct {
+	// What happens with a duplicate comment, none disables detection.
+	Action moderation.Action
+	// Minimal similarity of duplicate comments.
+	Threshold float64
+	// Comment is a duplicate when it matches a comment of the same user, or at
+	// least MinMatches comments of any users created within Window.
+	MinMatches int
+	Window     time.Duration
+	// Shorter comments are not compared.
+	MinLength int
+}
+
+// Returns detection options from configuration.
+func OptionsFrom(config config.Config) Options {
+	return Options{
+		Action:     moderation.Action(config.DuplicateAction),
+		Threshold:  config.DuplicateThreshold,
+		MinMatches: config.DuplicateMinMatches,
+		Window:     config.DuplicateWindow,
+		MinLength:  config.DuplicateMinLength,
+	}
+}
@@ -0,0 +1,126 @@
+package similarity
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// Length of character shingles.
+	shingleSize = 5
+	// Number of MinHash values in a signature.
+	signatureSize = 128
+	// Signature is split into bands of rows for locality sensitive hashing.
+	// Comments that share a band are candidates for comparison, which finds most
+	// pairs with similarity above 0.5.
+	bandCount = 32
+	bandRows  = signatureSize / bandCount
+)
+
+// Seeds of hash functions. They are fixed, so that all replicas compute equal
+// signatures of equal comments.
+var seeds = func() [signatureSize]uint64 {
+	var result [signatureSize]uint64
+	state := uint64(0x2545f4914f6cdd1d)
+	for i := range result {
+		state = splitmix64(state)
+		result[i] = state
+	}
+	return result
+}()
+
+// MinHash signature of a comment.
+type Signature []int64
+
+// Computes signature of text. Returns false for texts shorter than minLength
+// characters after normalization, which are too short to compare.
+func NewSignature(text string, minLength int) (Signature, bool) {
+	normalized := []rune(normalize(text))
+	if len(normalized) < minLength || len(normalized) < shingleSize {
+		return nil, false
+	}
+
+	signature := make(Signature, signatureSize)
+	for i := range signature {
+		signature[i] = int64(^uint64(0) >> 1)
+	}
+
+	for i := 0; i+shingleSize <= len(normalized); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(string(normalized[i : i+shingleSize])))
+		shingle := h.Sum64()
+
+		for j, seed := range seeds {
+			// Values are kept positive, so that they fit in BIGINT as they are.
+			value := int64(splitmix64(shingle^seed) >> 1)
+			if value < signature[j] {
+				signature[j] = value
+			}
+		}
+	}
+
+	return signature, true
+}
+
+// Estimates Jaccard similarity of shingles of two comments, from 0 to 1.
+func (s Signature) Similarity(other Signature) float64 {
+	if len(s) != len(other) || len(s) == 0 {
+		return 0
+	}
+
+	equal := 0
+	for i := range s {
+		if s[i] == other[i] {
+			equal++
+		}
+	}
+
+	return float64(equal) / float64(len(s))
+}
+
+// Returns hash of each band of the signature.
+func (s Signature) Bands() []int64 {
+	bands := make([]int64, 0, bandCount)
+	buf := make([]byte, 8)
+	for band := 0; band < bandCount; band++ {
+		h := fnv.New64a()
+		binary.LittleEndian.PutUint64(buf, uint64(band))
+		h.Write(buf)
+		for _, value := range s[band*bandRows : (band+1)*bandRows] {
+			binary.LittleEndian.PutUint64(buf, uint64(value))
+			h.Write(buf)
+		}
+		bands = append(bands, int64(h.Sum64()))
+	}
+
+	return bands
+}
+
+// Lower cases text and keeps only letters and digits separated by single spaces,
+// so that punctuation and spacing don't hide duplicates.
+func normalize(text string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteRune(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		} else {
+			space = true
+		}
+	}
+
+	return b.String()
+}
+
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -0,0 +1,57 @@
+package similarity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func signature(t *testing.T, text string) Signature {
+	s, ok := NewSignature(text, 20)
+	require.True(t, ok, text)
+	return s
+}
+
+func TestSimilarity(t *testing.T) {
+	original := signature(t, "Polnilnica ne deluje že tretji dan, kabel je poškodovan. Ne priporočam!")
+
+	// Punctuation, case and spacing are ignored.
+	same := signature(t, "polnilnica NE deluje že tretji dan   kabel je poškodovan ne priporočam")
+	require.Equal(t, 1.0, original.Similarity(same))
+
+	// Small edits keep comments similar.
+	edited := signature(t, "Polnilnica ne deluje že četrti dan, kabel je poškodovan. Ne priporočam!!")
+	require.Greater(t, original.Similarity(edited), 0.7)
+
+	other := signature(t, "Hitro polnjenje in prijazno osebje, kava v bližini je odlična.")
+	require.Less(t, original.Similarity(other), 0.2)
+}
+
+func TestBands(t *testing.T) {
+	original := signature(t, "Charger was broken again, the cable is damaged and nobody fixes it.")
+	edited := signature(t, "Charger was broken again, the cable is damaged and nobody fixes it!!! Avoid.")
+	other := signature(t, "Fast charging, friendly staff and a nice coffee shop next door.")
+
+	require.Len(t, original.Bands(), bandCount)
+	require.True(t, shareBand(original.Bands(), edited.Bands()))
+	require.False(t, shareBand(original.Bands(), other.Bands()))
+}
+
+func TestShortText(t *testing.T) {
+	_, ok := NewSignature("Super!", 20)
+	require.False(t, ok)
+
+	// Signatures are stable across runs and replicas.
+	s1, _ := NewSignature("The same comment posted everywhere", 20)
+	s2, _ := NewSignature("The same comment posted everywhere", 20)
+	require.Equal(t, s1, s2)
+}
+
+func shareBand(a, b []int64) bool {
+	for i := range a {
+		if a[i] == b[i] {
+			return true
+		}
+	}
+	return false
+}
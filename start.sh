@@ -2,9 +2,5 @@
 
 set -e
 
-echo "run db migration"
-db_source=$(jq -r .db_source config.json)
-/app/migrate -path /app/migration -database "$db_source" -verbose up
-
 echo "start the app"
 exec "$@"
\ No newline at end of file
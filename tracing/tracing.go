@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"rating-service/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterNone   = "none"
+
+	ServiceName = "rating-service"
+)
+
+// Sets up global tracer provider with exporter selected in configuration and
+// W3C trace context propagation. Tracing is disabled by default. Returned
+// function flushes remaining spans and stops the exporter.
+func Setup(ctx context.Context, config config.Config) (func(context.Context) error, error) {
+
+	// Incoming trace context is propagated even when spans aren't exported.
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch config.TracingExporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		options := []otlptracegrpc.Option{otlptracegrpc.WithInsecure()}
+		if config.OTLPEndpoint != "" {
+			options = append(options, otlptracegrpc.WithEndpoint(config.OTLPEndpoint))
+		}
+		exporter, err = otlptracegrpc.New(ctx, options...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", config.TracingExporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Sample all traces unless ratio is configured, and follow decisions of callers.
+	ratio := config.TracingSampleRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
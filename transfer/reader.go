@@ -0,0 +1,202 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"rating-service/db"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+// Layouts accepted for created_at column.
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"}
+
+// Creates import source for given format.
+func NewReader(format string, r io.Reader) (db.ImportSource, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatNDJSON:
+		return newNDJSONReader(r), nil
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+}
+
+// Reads CSV with a header row. Columns station_id, user_id and rating are
+// required, comment and created_at are optional.
+type csvReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("missing csv header")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range []string{"station_id", "user_id", "rating"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing csv column %q", name)
+		}
+	}
+
+	return &csvReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvReader) Next() (db.ImportRecord, error) {
+	fields, err := r.reader.Read()
+	if err == io.EOF {
+		return db.ImportRecord{}, io.EOF
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return db.ImportRecord{Row: parseErr.StartLine, Err: parseErr.Err}, nil
+	}
+	if err != nil {
+		return db.ImportRecord{}, err
+	}
+
+	line, _ := r.reader.FieldPos(0)
+	record := db.ImportRecord{Row: line}
+	record.Rating, record.Err = r.decode(fields)
+
+	return record, nil
+}
+
+func (r *csvReader) decode(fields []string) (rating db.ImportRatingParam, err error) {
+	value := func(name string) string {
+		i, ok := r.columns[name]
+		if !ok || i >= len(fields) {
+			return ""
+		}
+		return strings.TrimSpace(fields[i])
+	}
+
+	if rating.Station_id, err = parseInt("station_id", value("station_id")); err != nil {
+		return
+	}
+	if rating.User_id, err = parseInt("user_id", value("user_id")); err != nil {
+		return
+	}
+	if rating.Rating, err = parseInt("rating", value("rating")); err != nil {
+		return
+	}
+	if i, ok := r.columns["comment"]; ok && i < len(fields) {
+		rating.Comment = fields[i]
+	}
+	rating.CreatedAt, err = parseTime(value("created_at"))
+
+	return
+}
+
+// Reads newline delimited JSON objects, blank lines are skipped.
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+type ndjsonRating struct {
+	Station_id int64  `json:"station_id"`
+	User_id    int64  `json:"user_id"`
+	Rating     int64  `json:"rating"`
+	Comment    string `json:"comment"`
+	CreatedAt  string `json:"created_at"`
+}
+
+func newNDJSONReader(r io.Reader) *ndjsonReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	return &ndjsonReader{scanner: scanner}
+}
+
+func (r *ndjsonReader) Next() (db.ImportRecord, error) {
+	for r.scanner.Scan() {
+		r.line++
+
+		data := bytes.TrimSpace(r.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		record := db.ImportRecord{Row: r.line}
+
+		var row ndjsonRating
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row); err != nil {
+			record.Err = err
+			return record, nil
+		}
+
+		record.Rating = db.ImportRatingParam{
+			Station_id: row.Station_id,
+			User_id:    row.User_id,
+			Rating:     row.Rating,
+			Comment:    row.Comment,
+		}
+		record.Rating.CreatedAt, record.Err = parseTime(row.CreatedAt)
+
+		return record, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return db.ImportRecord{}, err
+	}
+
+	return db.ImportRecord{}, io.EOF
+}
+
+func parseInt(name, value string) (int64, error) {
+	if value == "" {
+		return 0, fmt.Errorf("%s is required", name)
+	}
+
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number", name)
+	}
+
+	return number, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("created_at must be a RFC 3339 timestamp")
+}
@@ -0,0 +1,82 @@
+package transfer
+
+import (
+	"io"
+	"rating-service/db"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, source db.ImportSource) []db.ImportRecord {
+	var records []db.ImportRecord
+	for {
+		record, err := source.Next()
+		if err == io.EOF {
+			return records
+		}
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	const input = `station_id,user_id,rating,comment,created_at
+1,21,3,"Povprečna polnilnica, težave pri parkiranju.",2021-12-01T10:00:00Z
+2,4,five,,
+3,5,4,Dobra,
+`
+	source, err := NewReader(FormatCSV, strings.NewReader(input))
+	require.NoError(t, err)
+
+	records := readAll(t, source)
+	require.Len(t, records, 3)
+
+	require.NoError(t, records[0].Err)
+	require.Equal(t, 2, records[0].Row)
+	require.Equal(t, int64(1), records[0].Rating.Station_id)
+	require.Equal(t, "Povprečna polnilnica, težave pri parkiranju.", records[0].Rating.Comment)
+	require.Equal(t, time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC), records[0].Rating.CreatedAt)
+
+	require.Error(t, records[1].Err)
+	require.Equal(t, 3, records[1].Row)
+
+	require.NoError(t, records[2].Err)
+	require.True(t, records[2].Rating.CreatedAt.IsZero())
+}
+
+func TestCSVReaderMissingColumn(t *testing.T) {
+	_, err := NewReader(FormatCSV, strings.NewReader("station_id,comment\n1,test\n"))
+	require.Error(t, err)
+}
+
+func TestNDJSONReader(t *testing.T) {
+	const input = `{"station_id": 1, "user_id": 2, "rating": 4, "comment": "Bil ponovno, še vedno dobra."}
+
+{"station_id": 1, "user_id": 2, "rating": 4, "unknown": true}
+{"station_id": 2, "user_id": 4, "rating": 5, "created_at": "2021-11-30 08:15:00"}
+`
+	source, err := NewReader(FormatNDJSON, strings.NewReader(input))
+	require.NoError(t, err)
+
+	records := readAll(t, source)
+	require.Len(t, records, 3)
+
+	require.NoError(t, records[0].Err)
+	require.Equal(t, 1, records[0].Row)
+	require.Equal(t, "Bil ponovno, še vedno dobra.", records[0].Rating.Comment)
+
+	require.Error(t, records[1].Err)
+	require.Equal(t, 3, records[1].Row)
+
+	require.NoError(t, records[2].Err)
+	require.Equal(t, 4, records[2].Row)
+	require.Equal(t, time.Date(2021, 11, 30, 8, 15, 0, 0, time.UTC), records[2].Rating.CreatedAt)
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	_, err := NewReader("xml", strings.NewReader(""))
+	require.Error(t, err)
+}
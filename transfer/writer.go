@@ -0,0 +1,160 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"rating-service/db"
+	"strconv"
+	"time"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// Size of parquet row groups kept in memory before they are flushed.
+const parquetRowGroupSize = 8 * 1024 * 1024
+
+var csvHeader = []string{"rating_id", "station_id", "user_id", "rating", "comment", "created_at", "status", "moderation_reasons"}
+
+// Encodes ratings one by one. Close must be called to flush buffered data.
+type Writer interface {
+	Write(rating db.Rating) error
+	Close() error
+}
+
+// Creates export writer for given format.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	case FormatParquet:
+		return newParquetWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// Returns content type of given export format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, err
+	}
+
+	return &csvWriter{writer: writer}, nil
+}
+
+func (w *csvWriter) Write(rating db.Rating) error {
+	reasons, err := moderationReasons(rating)
+	if err != nil {
+		return err
+	}
+
+	return w.writer.Write([]string{
+		strconv.FormatInt(rating.ID, 10),
+		strconv.FormatInt(rating.Station_id, 10),
+		strconv.FormatInt(rating.User_id, 10),
+		strconv.FormatInt(rating.Rating, 10),
+		rating.Comment,
+		rating.CreatedAt.Format(time.RFC3339Nano),
+		rating.Status,
+		reasons,
+	})
+}
+
+func (w *csvWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonWriter) Write(rating db.Rating) error {
+	return w.encoder.Encode(rating)
+}
+
+func (w *ndjsonWriter) Close() error {
+	return nil
+}
+
+type parquetRating struct {
+	RatingID  int64  `parquet:"name=rating_id, type=INT64"`
+	StationID int64  `parquet:"name=station_id, type=INT64"`
+	UserID    int64  `parquet:"name=user_id, type=INT64"`
+	Rating    int64  `parquet:"name=rating, type=INT64"`
+	Comment   string `parquet:"name=comment, type=BYTE_ARRAY, convertedtype=UTF8"`
+	CreatedAt int64  `parquet:"name=created_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	Status    string `parquet:"name=status, type=BYTE_ARRAY, convertedtype=UTF8"`
+	// JSON array of reasons, like in NDJSON export.
+	ModerationReasons string `parquet:"name=moderation_reasons, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+type parquetWriter struct {
+	writer *writer.ParquetWriter
+}
+
+func newParquetWriter(w io.Writer) (*parquetWriter, error) {
+	pw, err := writer.NewParquetWriterFromWriter(w, new(parquetRating), 1)
+	if err != nil {
+		return nil, err
+	}
+
+	pw.RowGroupSize = parquetRowGroupSize
+	pw.CompressionType = parquet.CompressionCodec_SNAPPY
+
+	return &parquetWriter{writer: pw}, nil
+}
+
+func (w *parquetWriter) Write(rating db.Rating) error {
+	reasons, err := moderationReasons(rating)
+	if err != nil {
+		return err
+	}
+
+	return w.writer.Write(parquetRating{
+		RatingID:          rating.ID,
+		StationID:         rating.Station_id,
+		UserID:            rating.User_id,
+		Rating:            rating.Rating,
+		Comment:           rating.Comment,
+		CreatedAt:         rating.CreatedAt.UnixMilli(),
+		Status:            rating.Status,
+		ModerationReasons: reasons,
+	})
+}
+
+func (w *parquetWriter) Close() error {
+	return w.writer.WriteStop()
+}
+
+// Encodes moderation reasons of a rating as a JSON array.
+func moderationReasons(rating db.Rating) (string, error) {
+	reasons := rating.ModerationReasons
+	if reasons == nil {
+		reasons = db.ModerationReasons{}
+	}
+
+	data, err := json.Marshal(reasons)
+	return string(data), err
+}
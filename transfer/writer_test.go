@@ -0,0 +1,79 @@
+package transfer
+
+import (
+	"bytes"
+	"rating-service/db"
+	"rating-service/moderation"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testRatings = []db.Rating{
+	{ID: 1, Station_id: 1, User_id: 21, Rating: 3, Comment: "Povprečna polnilnica, težave pri parkiranju.", CreatedAt: time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)},
+	{ID: 2, Station_id: 2, User_id: 4, Rating: 5, Comment: "Nevrjetn dobr! :)", CreatedAt: time.Date(2021, 12, 2, 10, 0, 0, 0, time.UTC)},
+	{
+		ID: 3, Station_id: 2, User_id: 5, Rating: 1, Comment: "Call ***********", CreatedAt: time.Date(2021, 12, 3, 10, 0, 0, 0, time.UTC),
+		Status:            db.StatusPending,
+		ModerationReasons: db.ModerationReasons{{Filter: "phone", Action: moderation.ActionModerate, Reason: `phone number "041 123 456"`}},
+	},
+}
+
+func writeAll(t *testing.T, format string) []byte {
+	var buf bytes.Buffer
+
+	writer, err := NewWriter(format, &buf)
+	require.NoError(t, err)
+
+	for _, rating := range testRatings {
+		require.NoError(t, writer.Write(rating))
+	}
+	require.NoError(t, writer.Close())
+
+	return buf.Bytes()
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	data := writeAll(t, FormatCSV)
+
+	source, err := NewReader(FormatCSV, bytes.NewReader(data))
+	require.NoError(t, err)
+
+	records := readAll(t, source)
+	require.Len(t, records, len(testRatings))
+	for i, record := range records {
+		require.NoError(t, record.Err)
+		require.Equal(t, testRatings[i].Station_id, record.Rating.Station_id)
+		require.Equal(t, testRatings[i].Comment, record.Rating.Comment)
+		require.Equal(t, testRatings[i].CreatedAt, record.Rating.CreatedAt)
+	}
+}
+
+func TestCSVWriterStatus(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(string(writeAll(t, FormatCSV))), "\n")
+	require.Len(t, lines, len(testRatings)+1)
+	require.Equal(t, "rating_id,station_id,user_id,rating,comment,created_at,status,moderation_reasons", lines[0])
+	require.True(t, strings.HasSuffix(lines[3], `,pending,"[{""filter"":""phone"",""action"":""moderate"",""reason"":""phone number \""041 123 456\""""}]"`), lines[3])
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	data := writeAll(t, FormatNDJSON)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, len(testRatings))
+	require.Contains(t, lines[1], `"comment":"Nevrjetn dobr! :)"`)
+}
+
+func TestParquetWriter(t *testing.T) {
+	data := writeAll(t, FormatParquet)
+
+	// Parquet files start and end with a magic number.
+	require.True(t, bytes.HasPrefix(data, []byte("PAR1")))
+	require.True(t, bytes.HasSuffix(data, []byte("PAR1")))
+
+	// Schema in the footer has status and moderation reasons like other formats.
+	require.Contains(t, string(data), "status")
+	require.Contains(t, string(data), "moderation_reasons")
+}
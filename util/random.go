@@ -2,7 +2,9 @@ package util
 
 import (
 	"math/rand"
+	"rating-service/seed"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -27,3 +29,26 @@ func RandomString(n int) string {
 
 	return sb.String()
 }
+
+// Generator of realistic stars and comments, seeded like the rest of random helpers.
+var generator = struct {
+	sync.Mutex
+	*seed.Generator
+}{Generator: seed.New(seed.Options{Seed: time.Now().UnixNano()})}
+
+// Returns number of stars with distribution of real ratings.
+func RandomStars() int64 {
+	generator.Lock()
+	defer generator.Unlock()
+
+	return generator.Stars()
+}
+
+// Returns realistic comment in Slovenian or English that matches given stars.
+func RandomComment(stars int64) string {
+	generator.Lock()
+	defer generator.Unlock()
+
+	comment, _ := generator.Comment(stars)
+	return comment
+}